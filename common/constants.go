@@ -56,6 +56,31 @@ const (
 	TimeInForceIoc = "IOC"
 )
 
+// Assets
+const (
+	AssetUsdc = "USDC"
+)
+
+// Transfer Types
+const (
+	TransferTypeDeposit        = "DEPOSIT"
+	TransferTypeWithdrawal     = "WITHDRAWAL"
+	TransferTypeFastWithdrawal = "FAST_WITHDRAWAL"
+	TransferTypeTransferOut    = "TRANSFER_OUT"
+	TransferTypeTransferIn     = "TRANSFER_IN"
+)
+
+// Liquidity Types
+const (
+	LiquidityMaker = "MAKER"
+	LiquidityTaker = "TAKER"
+)
+
+// Pagination
+const (
+	MaxPageLimit = 100
+)
+
 const (
 	OrderStatusPending     = "PENDING"
 	OrderStatusOpen        = "OPEN"
@@ -12,8 +12,14 @@ func getUserId(address string) string {
 	return uuid.NewV5(namespace, address).String()
 }
 
+// GetAccountId 默认账户(account number 为 0)的账户 id
 func GetAccountId(address string) string {
-	return uuid.NewV5(namespace, getUserId(strings.ToLower(address))+strconv.Itoa(0)).String()
+	return GetAccountIdByNumber(address, 0)
+}
+
+// GetAccountIdByNumber 同一钱包下指定 account number 的账户 id
+func GetAccountIdByNumber(address string, accountNumber int) string {
+	return uuid.NewV5(namespace, getUserId(strings.ToLower(address))+strconv.Itoa(accountNumber)).String()
 }
 
 func FromString(input string) (u uuid.UUID, err error) {
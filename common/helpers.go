@@ -53,3 +53,7 @@ func HashString(input string) string {
 func ExpireAfter(duration time.Duration) string {
 	return time.Now().Add(duration).UTC().Format("2006-01-02T15:04:05.999Z")
 }
+
+func GenerateNowISO() string {
+	return time.Now().UTC().Format("2006-01-02T15:04:05.999Z")
+}
@@ -0,0 +1,183 @@
+package common
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/yanue/starkex"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	starkTransferPrefix             = 4
+	starkTransferPositionIdBits     = 64
+	starkTransferNonceBits          = 32
+	starkTransferQuantumsAmountBits = 64
+	starkTransferExpirationBits     = 32
+	starkTransferPaddingBits        = 81
+)
+
+// STARK curve: y^2 = x^3 + alpha*x + beta (mod FIELD_PRIME), alpha = 1
+var (
+	starkEcGenX, _ = new(big.Int).SetString("1ef15c18599971b7beced415a40f0c7deacfd9b0d1819e03d723d8bc943cfca", 16)
+	starkEcGenY, _ = new(big.Int).SetString("5668060aa49730b7be4801df46ec62de53ecd11abe43a32873000c36e8dc1f", 16)
+	starkAlpha     = big.NewInt(1)
+)
+
+type starkPoint struct {
+	x, y *big.Int
+}
+
+// StarkPrivateKeyToPublicKeyPair 由 STARK 私钥计算公钥的 x, y 坐标, 均为 0x 前缀的十六进制
+func StarkPrivateKeyToPublicKeyPair(privateKey string) (string, string, error) {
+	key, ok := new(big.Int).SetString(strings.TrimPrefix(privateKey, "0x"), 16)
+	if !ok || key.Sign() <= 0 || key.Cmp(starkex.EC_ORDER) >= 0 {
+		return "", "", fmt.Errorf("invalid stark private key: %s", privateKey)
+	}
+	point := starkEcMult(key, &starkPoint{starkEcGenX, starkEcGenY})
+	return "0x" + point.x.Text(16), "0x" + point.y.Text(16), nil
+}
+
+// starkEcMult double-and-add, nil 表示无穷远点
+func starkEcMult(m *big.Int, point *starkPoint) *starkPoint {
+	var result *starkPoint
+	addend := point
+	for i := 0; i < m.BitLen(); i++ {
+		if m.Bit(i) == 1 {
+			result = starkEcAdd(result, addend)
+		}
+		addend = starkEcAdd(addend, addend)
+	}
+	return result
+}
+
+func starkEcAdd(p1, p2 *starkPoint) *starkPoint {
+	if p1 == nil {
+		return p2
+	}
+	if p2 == nil {
+		return p1
+	}
+	prime := starkex.FIELD_PRIME
+	var slope *big.Int
+	if p1.x.Cmp(p2.x) == 0 {
+		sum := new(big.Int).Add(p1.y, p2.y)
+		if sum.Mod(sum, prime).Sign() == 0 {
+			return nil
+		}
+		// slope = (3 * x^2 + alpha) / (2 * y)
+		numerator := new(big.Int).Mul(p1.x, p1.x)
+		numerator.Mul(numerator, big.NewInt(3)).Add(numerator, starkAlpha)
+		denominator := new(big.Int).Lsh(p1.y, 1)
+		slope = starkDivMod(numerator, denominator, prime)
+	} else {
+		// slope = (y1 - y2) / (x1 - x2)
+		slope = starkDivMod(new(big.Int).Sub(p1.y, p2.y), new(big.Int).Sub(p1.x, p2.x), prime)
+	}
+	x := new(big.Int).Mul(slope, slope)
+	x.Sub(x, p1.x).Sub(x, p2.x).Mod(x, prime)
+	y := new(big.Int).Sub(p1.x, x)
+	y.Mul(y, slope).Sub(y, p1.y).Mod(y, prime)
+	return &starkPoint{x, y}
+}
+
+func starkDivMod(n, m, p *big.Int) *big.Int {
+	inverse := new(big.Int).ModInverse(new(big.Int).Mod(m, p), p)
+	result := new(big.Int).Mul(n, inverse)
+	return result.Mod(result, p)
+}
+
+// StarkSign 使用 STARK 私钥对 pedersen 哈希(十进制字符串)做 ECDSA 签名, 返回 r || s 各 32 字节的十六进制
+func StarkSign(privateKey, msgHash string) (string, error) {
+	key, ok := new(big.Int).SetString(strings.TrimPrefix(privateKey, "0x"), 16)
+	if !ok || key.Sign() <= 0 || key.Cmp(starkex.EC_ORDER) >= 0 {
+		return "", fmt.Errorf("invalid stark private key: %s", privateKey)
+	}
+	hash, ok := new(big.Int).SetString(msgHash, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid message hash: %s", msgHash)
+	}
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(starkex.N_ELEMENT_BITS_ECDSA.Int64()))
+	generator := &starkPoint{starkEcGenX, starkEcGenY}
+	for seed := 0; ; seed++ {
+		k := starkex.GenerateKRfc6979(hash, key, seed)
+		r := starkEcMult(k, generator).x
+		if r.Sign() <= 0 || r.Cmp(bound) >= 0 {
+			continue
+		}
+		// w = k / (msgHash + r * privateKey) mod EC_ORDER
+		sum := new(big.Int).Mul(r, key)
+		sum.Add(sum, hash)
+		if new(big.Int).Mod(sum, starkex.EC_ORDER).Sign() == 0 {
+			continue
+		}
+		w := starkDivMod(k, sum, starkex.EC_ORDER)
+		if w.Sign() <= 0 || w.Cmp(bound) >= 0 {
+			continue
+		}
+		s := starkDivMod(big.NewInt(1), w, starkex.EC_ORDER)
+		return starkex.SerializeSignature(r, s), nil
+	}
+}
+
+// StarkTransferParam 普通(非条件)转账的签名参数, 手续费固定为 0
+type StarkTransferParam struct {
+	NetworkId          int
+	SenderPositionId   int64
+	ReceiverPositionId int64
+	ReceiverPublicKey  string
+	HumanAmount        string
+	ClientId           string
+	Expiration         string // 2006-01-02T15:04:05.000Z
+}
+
+// StarkTransferSign 对账户间转账签名
+func StarkTransferSign(privateKey string, param StarkTransferParam) (string, error) {
+	hash, err := getStarkTransferHash(param)
+	if err != nil {
+		return "", err
+	}
+	return StarkSign(privateKey, hash)
+}
+
+func getStarkTransferHash(param StarkTransferParam) (string, error) {
+	assetId := starkex.COLLATERAL_ASSET_ID_BY_NETWORK_ID[param.NetworkId]
+	if assetId == nil {
+		return "", fmt.Errorf("invalid network_id: %v", param.NetworkId)
+	}
+	receiverPublicKey, ok := new(big.Int).SetString(strings.TrimPrefix(param.ReceiverPublicKey, "0x"), 16)
+	if !ok {
+		return "", fmt.Errorf("invalid receiver public key: %s", param.ReceiverPublicKey)
+	}
+	amount, err := decimal.NewFromString(param.HumanAmount)
+	if err != nil {
+		return "", err
+	}
+	quantumsAmount := amount.Mul(decimal.NewFromInt(starkex.ASSET_RESOLUTION[starkex.COLLATERAL_ASSET]))
+	if !quantumsAmount.IsInteger() || !quantumsAmount.IsPositive() {
+		return "", fmt.Errorf("invalid transfer amount: %s", param.HumanAmount)
+	}
+	expiration, err := time.Parse("2006-01-02T15:04:05.000Z", param.Expiration)
+	if err != nil {
+		return "", err
+	}
+	expirationEpochHours := int64(math.Ceil(float64(expiration.Unix()) / float64(starkex.ONE_HOUR_IN_SECONDS)))
+
+	// part1 = H(H(assetId, assetIdFee), receiverPublicKey)
+	part1 := starkex.PedersenHash(starkex.PedersenHash(assetId.String(), "0"), receiverPublicKey.String())
+	// part2 = senderPositionId | receiverPositionId | feePositionId(sender) | nonce
+	part2 := big.NewInt(param.SenderPositionId)
+	part2.Lsh(part2, starkTransferPositionIdBits).Add(part2, big.NewInt(param.ReceiverPositionId))
+	part2.Lsh(part2, starkTransferPositionIdBits).Add(part2, big.NewInt(param.SenderPositionId))
+	part2.Lsh(part2, starkTransferNonceBits).Add(part2, starkex.NonceByClientId(param.ClientId))
+	// part3 = prefix | quantumsAmount | maxAmountFee(0) | expirationEpochHours | padding
+	part3 := big.NewInt(starkTransferPrefix)
+	part3.Lsh(part3, starkTransferQuantumsAmountBits).Add(part3, quantumsAmount.BigInt())
+	part3.Lsh(part3, starkTransferQuantumsAmountBits)
+	part3.Lsh(part3, starkTransferExpirationBits).Add(part3, big.NewInt(expirationEpochHours))
+	part3.Lsh(part3, starkTransferPaddingBits)
+
+	return starkex.PedersenHash(starkex.PedersenHash(part1, part2.String()), part3.String()), nil
+}
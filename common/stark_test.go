@@ -0,0 +1,62 @@
+package common
+
+import (
+	"github.com/yanue/starkex"
+	"math/big"
+	"testing"
+)
+
+func TestStarkTransferSign(t *testing.T) {
+	const privateKey = "0x58c7d5a90b1776bde86ebac077e053ed85b0f7164f53b080304a531947f46e3"
+	param := StarkTransferParam{
+		NetworkId:          NetworkIdRopsten,
+		SenderPositionId:   12345,
+		ReceiverPositionId: 67890,
+		ReceiverPublicKey:  "05135ef87716b0faecec3ba672d145a6daad0aa46437c365d490022115aba674",
+		HumanAmount:        "49.478023",
+		ClientId:           "This is an ID that the client came up with to describe this transfer",
+		Expiration:         "2020-09-17T04:15:55.028Z",
+	}
+	publicKey, _, err := StarkPrivateKeyToPublicKeyPair(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// MOCK_PUBLIC_KEY of the official clients
+	if publicKey != "0x3b865a18323b8d147a12c556bfb1d502516c325b1477a23ba6c77af31f020fd" {
+		t.Fatalf("unexpected public key %s", publicKey)
+	}
+
+	// 按 StarkEx 转账消息格式手工打包的字段, 不经过 getStarkTransferHash:
+	// part2 = 12345 | 67890 | 12345 | nonce(1723841828), part3 = 4 | 49478023 | 0 | 444533 | 81 位填充
+	words := []string{
+		"0x02c04d8b650f44092278a7cb1e1028c82025dff622db96c934b611b84cc8de5a",
+		"0x0",
+		"0x05135ef87716b0faecec3ba672d145a6daad0aa46437c365d490022115aba674",
+		"0x30390000000000010932000000000000303966bfbd24",
+		"0x80000000005e5f30e0000000000000000000d90ea00000000000000000000",
+	}
+	expected := ""
+	for i, word := range words {
+		value, _ := new(big.Int).SetString(word, 0)
+		if i == 0 {
+			expected = value.String()
+			continue
+		}
+		expected = starkex.PedersenHash(expected, value.String())
+	}
+	hash, err := getStarkTransferHash(param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != expected {
+		t.Errorf("expected hash %s, got %s", expected, hash)
+	}
+
+	signature, err := StarkTransferSign(privateKey, param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedSignature, _ := StarkSign(privateKey, expected); signature != expectedSignature {
+		t.Errorf("expected signature %s, got %s", expectedSignature, signature)
+	}
+}
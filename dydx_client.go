@@ -1,10 +1,13 @@
 package dydx
 
 import (
+	"fmt"
 	"github.com/umbracle/go-web3/jsonrpc"
 	"github.com/verichenn/dydx-v3-go/common"
 	"github.com/verichenn/dydx-v3-go/modules"
+	"github.com/verichenn/dydx-v3-go/ws"
 	"log"
+	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -15,6 +18,7 @@ type Client struct {
 
 	Host                      string
 	ApiTimeout                time.Duration
+	HttpClient                *http.Client
 	StarkPublicKey            string
 	StarkPrivateKey           string
 	StarkPublicKeyYCoordinate string
@@ -26,31 +30,55 @@ type Client struct {
 	NetworkId      int
 	Logger         *log.Logger
 
+	Public     *modules.Public
 	Private    *modules.Private
+	EthPrivate *modules.EthPrivate
 	OnBoarding *modules.OnBoarding
+	Ws         *ws.Client
 }
 
 type Options struct {
 	Host                      string
+	WsHost                    string
 	StarkPublicKey            string
 	StarkPrivateKey           string
 	StarkPublicKeyYCoordinate string
 	DefaultEthereumAddress    string
+	AccountNumber             int
+	EthPrivateKey             string
 	ApiKeyCredentials         *modules.ApiKeyCredentials
 
 	Web3      *jsonrpc.Client
 	NetworkId int
+
+	// HttpClient 不为空时所有 REST 请求共用该客户端, 此时忽略 Transport 与 ApiTimeout
+	HttpClient *http.Client
+	Transport  http.RoundTripper
+	ApiTimeout time.Duration
+
+	// RetryPolicy 为空时使用 modules.DefaultRetryPolicy(), 传入 &modules.RetryPolicy{} 关闭重试
+	RetryPolicy *modules.RetryPolicy
 }
 
 func NewClient(options Options) *Client {
 	client := &Client{
-		Host:              strings.TrimPrefix(options.Host, "/"),
-		ApiTimeout:        3 * time.Second,
-		DefaultAddress:    options.DefaultEthereumAddress,
-		StarkPublicKey:    options.StarkPublicKey,
-		StarkPrivateKey:   options.StarkPrivateKey,
-		ApiKeyCredentials: options.ApiKeyCredentials,
-		Logger:            log.New(os.Stderr, "dydx-v3-go ", log.LstdFlags),
+		Host:                      strings.TrimPrefix(options.Host, "/"),
+		ApiTimeout:                options.ApiTimeout,
+		HttpClient:                options.HttpClient,
+		DefaultAddress:            options.DefaultEthereumAddress,
+		NetworkId:                 options.NetworkId,
+		StarkPublicKey:            options.StarkPublicKey,
+		StarkPrivateKey:           options.StarkPrivateKey,
+		StarkPublicKeyYCoordinate: options.StarkPublicKeyYCoordinate,
+		ApiKeyCredentials:         options.ApiKeyCredentials,
+		Logger:                    log.New(os.Stderr, "dydx-v3-go ", log.LstdFlags),
+	}
+
+	if client.ApiTimeout == 0 {
+		client.ApiTimeout = 3 * time.Second
+	}
+	if client.HttpClient == nil {
+		client.HttpClient = modules.NewHttpClient(options.Transport, client.ApiTimeout)
 	}
 
 	if options.Web3 != nil {
@@ -69,29 +97,86 @@ func NewClient(options Options) *Client {
 		client.NetworkId = common.NetworkIdMainnet
 	}
 
-	if options.StarkPrivateKey != "" {
-		client.StarkPrivateKey = options.StarkPrivateKey
-		client.EthSigner = &modules.EthKeySinger{PrivateKey: options.StarkPrivateKey}
+	if options.EthPrivateKey != "" {
+		signer, err := modules.NewEthKeySinger(options.EthPrivateKey)
+		if err != nil {
+			panic(err)
+		}
+		if client.DefaultAddress == "" {
+			client.DefaultAddress = signer.Address
+		} else if !strings.EqualFold(client.DefaultAddress, signer.Address) {
+			panic(fmt.Sprintf("DefaultEthereumAddress %s does not match EthPrivateKey address %s",
+				client.DefaultAddress, signer.Address))
+		}
+		client.EthSigner = signer
+	}
+
+	if client.StarkPrivateKey != "" && (client.StarkPublicKey == "" || client.StarkPublicKeyYCoordinate == "") {
+		publicKey, publicKeyY, err := common.StarkPrivateKeyToPublicKeyPair(client.StarkPrivateKey)
+		if err != nil {
+			panic(err)
+		}
+		client.StarkPublicKey = publicKey
+		client.StarkPublicKeyYCoordinate = publicKeyY
 	}
 
-	client.OnBoarding = &modules.OnBoarding{
+	client.Public = &modules.Public{
 		Host:       client.Host,
-		EthSigner:  client.EthSigner,
-		NetworkId:  client.NetworkId,
-		EthAddress: client.DefaultAddress,
-		Singer:     modules.NewSigner(client.EthSigner, client.NetworkId),
+		HttpClient: client.HttpClient,
 		Logger:     client.Logger,
 	}
+
+	client.OnBoarding = &modules.OnBoarding{
+		Host:                      client.Host,
+		EthSigner:                 client.EthSigner,
+		NetworkId:                 client.NetworkId,
+		EthAddress:                client.DefaultAddress,
+		Singer:                    modules.NewSigner(client.EthSigner, client.NetworkId),
+		HttpClient:                client.HttpClient,
+		Logger:                    client.Logger,
+		StarkPublicKey:            client.StarkPublicKey,
+		StarkPublicKeyYCoordinate: client.StarkPublicKeyYCoordinate,
+	}
 	if options.ApiKeyCredentials == nil {
 		client.ApiKeyCredentials = client.OnBoarding.RecoverDefaultApiCredentials(client.DefaultAddress)
 	}
 
+	retryPolicy := options.RetryPolicy
+	if retryPolicy == nil {
+		retryPolicy = modules.DefaultRetryPolicy()
+	}
 	client.Private = &modules.Private{
 		Host:              client.Host,
 		NetworkId:         client.NetworkId,
 		StarkPrivateKey:   client.StarkPrivateKey,
 		DefaultAddress:    client.DefaultAddress,
+		AccountNumber:     options.AccountNumber,
+		ApiKeyCredentials: client.ApiKeyCredentials,
+		RetryPolicy:       retryPolicy,
+		HttpClient:        client.HttpClient,
+		Logger:            client.Logger,
+	}
+
+	client.EthPrivate = &modules.EthPrivate{
+		Host:           client.Host,
+		NetworkId:      client.NetworkId,
+		DefaultAddress: client.DefaultAddress,
+		Singer:         modules.NewEthPrivateSigner(client.EthSigner, client.NetworkId),
+		HttpClient:     client.HttpClient,
+		Logger:         client.Logger,
+	}
+
+	wsHost := options.WsHost
+	if wsHost == "" {
+		wsHost = common.WsHostRopsten
+		if client.NetworkId == common.NetworkIdMainnet {
+			wsHost = common.WsHostMainnet
+		}
+	}
+	client.Ws = &ws.Client{
+		Host:              wsHost,
 		ApiKeyCredentials: client.ApiKeyCredentials,
+		DefaultAddress:    client.DefaultAddress,
 		Logger:            client.Logger,
 	}
 	return client
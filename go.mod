@@ -4,19 +4,19 @@ go 1.17
 
 require (
 	github.com/ethereum/go-ethereum v1.10.13
+	github.com/gorilla/websocket v1.4.2
 	github.com/miguelmota/go-solidity-sha3 v0.1.1
 	github.com/satori/go.uuid v1.2.0
+	github.com/shopspring/decimal v1.3.1
 	github.com/umbracle/go-web3 v0.0.0-20211129204407-2291ba9e381d
 	github.com/yanue/starkex v0.0.0-20211122094927-61a9aa6b8d97
 )
 
 require (
 	github.com/btcsuite/btcd v0.21.0-beta // indirect
-	github.com/gorilla/websocket v1.4.2 // indirect
 	github.com/huandu/xstrings v1.3.2 // indirect
 	github.com/klauspost/compress v1.4.1 // indirect
 	github.com/klauspost/cpuid v1.2.0 // indirect
-	github.com/shopspring/decimal v1.3.1 // indirect
 	github.com/umbracle/fastrlp v0.0.0-20210128110402-41364ca56ca8 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasthttp v1.4.0 // indirect
@@ -1,10 +1,12 @@
 package modules
 
 import (
+	"context"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	solsha3 "github.com/miguelmota/go-solidity-sha3"
 	"github.com/verichenn/dydx-v3-go/common"
+	"math/big"
 )
 
 const (
@@ -38,41 +40,23 @@ func NewSigner(signer EthSigner, networkId int) *SignOnboardingAction {
 }
 
 func (a *SignOnboardingAction) Sign(signerAddress string, message map[string]interface{}) string {
+	typedSignature, err := a.SignWithContext(context.Background(), signerAddress, message)
+	if err != nil {
+		panic(err)
+	}
+	return typedSignature
+}
+
+// SignWithContext 同 Sign, 使用 web3 节点签名时 ctx 结束即返回 ctx.Err()
+func (a *SignOnboardingAction) SignWithContext(ctx context.Context, signerAddress string, message map[string]interface{}) (string, error) {
 	eip712Message := a.GetEIP712Message(message)
 	action := message["action"].(string)
 	messageHash := a.GetHash(action)
-	typedSignature := a.Signer.sign(eip712Message, messageHash, signerAddress)
-	return typedSignature
+	return a.Signer.sign(contextOrBackground(ctx), eip712Message, messageHash, signerAddress)
 }
 
 func (a *SignOnboardingAction) GetEIP712Message(message map[string]interface{}) map[string]interface{} {
-	structName := a.GetEIP712StructName()
-	eip712Message := map[string]interface{}{
-		"types": map[string]interface{}{
-			"EIP712Domain": []map[string]string{
-				{
-					"name": "name",
-					"type": "string",
-				},
-				{
-					"name": "version",
-					"type": "string",
-				},
-				{
-					"name": "chainId",
-					"type": "uint256",
-				},
-			},
-			structName: a.GetEIP712Struct(),
-		},
-		"domain": map[string]interface{}{
-			"name":    Domain,
-			"version": Version,
-			"chainId": a.NetworkId,
-		},
-		"primaryType": structName,
-		"message":     message,
-	}
+	eip712Message := getEIP712Message(a.NetworkId, a.GetEIP712StructName(), a.GetEIP712Struct(), message)
 	if a.NetworkId == common.NetworkIdMainnet {
 		msg := eip712Message["message"].(map[string]interface{})
 		msg["onlySignOn"] = "https://trade.dydx.exchange"
@@ -82,19 +66,11 @@ func (a *SignOnboardingAction) GetEIP712Message(message map[string]interface{})
 }
 
 func (a *SignOnboardingAction) GetEip712Hash(structHash string) string {
-	fact := solsha3.SoliditySHA3(
-		[]string{"bytes2", "bytes32", "bytes32"},
-		[]interface{}{"0x1901", a.GetDomainHash(), structHash},
-	)
-	return fmt.Sprintf("0x%x", fact)
+	return getEip712Hash(a.NetworkId, structHash)
 }
 
 func (a *SignOnboardingAction) GetDomainHash() string {
-	fact := solsha3.SoliditySHA3(
-		[]string{"bytes32", "bytes32", "bytes32", "uint256"},
-		[]interface{}{common.HashString(Eip712DomainStringNoContract), common.HashString(Domain), common.HashString(Version), a.NetworkId},
-	)
-	return fmt.Sprintf("0x%x", fact)
+	return getDomainHash(a.NetworkId)
 }
 
 func (a *SignOnboardingAction) GetEIP712Struct() []map[string]string {
@@ -116,14 +92,57 @@ func (a *SignOnboardingAction) GetHash(action string) string {
 	} else {
 		eip712StructStr = Eip712OnboardingActionStructStringTestnet
 	}
-	data := [][]string{
-		{"bytes32", "bytes32"},
-		{common.HashString(eip712StructStr), common.HashString(action)},
-	}
+	types := []string{"bytes32", "bytes32"}
+	values := []interface{}{common.HashString(eip712StructStr), common.HashString(action)}
 	if a.NetworkId == common.NetworkIdMainnet {
-		data[0] = append(data[0], "bytes32")
-		data[1] = append(data[1], common.HashString(OnlySignOnDomainMainnet))
+		types = append(types, "bytes32")
+		values = append(values, common.HashString(OnlySignOnDomainMainnet))
 	}
-	structHash := solsha3.SoliditySHA3(data[0], data[1])
+	structHash := solsha3.SoliditySHA3(types, values)
 	return a.GetEip712Hash(hexutil.Encode(structHash))
 }
+
+func getEIP712Message(networkId int, structName string, eip712Struct []map[string]string, message map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"types": map[string]interface{}{
+			"EIP712Domain": []map[string]string{
+				{
+					"name": "name",
+					"type": "string",
+				},
+				{
+					"name": "version",
+					"type": "string",
+				},
+				{
+					"name": "chainId",
+					"type": "uint256",
+				},
+			},
+			structName: eip712Struct,
+		},
+		"domain": map[string]interface{}{
+			"name":    Domain,
+			"version": Version,
+			"chainId": networkId,
+		},
+		"primaryType": structName,
+		"message":     message,
+	}
+}
+
+func getEip712Hash(networkId int, structHash string) string {
+	fact := solsha3.SoliditySHA3(
+		[]string{"bytes2", "bytes32", "bytes32"},
+		[]interface{}{"0x1901", getDomainHash(networkId), structHash},
+	)
+	return fmt.Sprintf("0x%x", fact)
+}
+
+func getDomainHash(networkId int) string {
+	fact := solsha3.SoliditySHA3(
+		[]string{"bytes32", "bytes32", "bytes32", "uint256"},
+		[]interface{}{common.HashString(Eip712DomainStringNoContract), common.HashString(Domain), common.HashString(Version), big.NewInt(int64(networkId))},
+	)
+	return fmt.Sprintf("0x%x", fact)
+}
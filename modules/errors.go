@@ -0,0 +1,83 @@
+package modules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 按 HTTP 状态码对 APIError 的分类, 可用 errors.Is 判断
+var (
+	ErrAuth        = errors.New("dydx: authentication failed")
+	ErrValidation  = errors.New("dydx: request validation failed")
+	ErrNotFound    = errors.New("dydx: resource not found")
+	ErrRateLimited = errors.New("dydx: rate limited")
+	ErrServer      = errors.New("dydx: server error")
+)
+
+// APIErrorDetail dYdX 错误响应中 errors 数组的元素
+type APIErrorDetail struct {
+	Msg      string `json:"msg"`
+	Param    string `json:"param"`
+	Location string `json:"location"`
+}
+
+// APIError 非 2xx 响应, 可用 errors.As 取出
+type APIError struct {
+	StatusCode  int
+	Method      string
+	RequestPath string
+	Errors      []APIErrorDetail
+	Body        string
+}
+
+func newAPIError(method, requestPath string, statusCode int, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode:  statusCode,
+		Method:      method,
+		RequestPath: requestPath,
+		Body:        string(body),
+	}
+	parsed := struct {
+		Errors []APIErrorDetail `json:"errors"`
+	}{}
+	// 错误响应不一定是 JSON, 解析失败时仅保留原始 Body
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		apiErr.Errors = parsed.Errors
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, detail := range e.Errors {
+		if detail.Param != "" {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", detail.Param, detail.Msg))
+		} else {
+			msgs = append(msgs, detail.Msg)
+		}
+	}
+	if len(msgs) == 0 {
+		return fmt.Sprintf("%s %s, status code: %d", e.Method, e.RequestPath, e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s, status code: %d, errors: %s", e.Method, e.RequestPath, e.StatusCode, strings.Join(msgs, "; "))
+}
+
+// Unwrap 返回状态码对应的分类错误, 无法分类时返回 nil
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAuth
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrServer
+	case e.StatusCode >= http.StatusBadRequest:
+		return ErrValidation
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/verichenn/dydx-v3-go/common"
+	"github.com/verichenn/dydx-v3-go/types"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+const emptyBody = "{}"
+
+// EthPrivate 使用以太坊签名(而非 API Key)鉴权的接口
+type EthPrivate struct {
+	Host           string
+	NetworkId      int
+	DefaultAddress string
+	Singer         *SignEthPrivateAction
+	HttpClient     *http.Client
+	Logger         *log.Logger
+	ctx            context.Context
+}
+
+// WithContext 返回使用 ctx 签名及发起请求的 EthPrivate
+func (p EthPrivate) WithContext(ctx context.Context) *EthPrivate {
+	p.ctx = ctx
+	return &p
+}
+
+// CreateApiKey 创建 API Key
+// see https://docs.dydx.exchange/?json#create-api-key
+func (p EthPrivate) CreateApiKey(ethereumAddress string) (*ApiKeyCredentials, error) {
+	res, err := p.request(http.MethodPost, "api-keys", ethereumAddress)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.ApiKeyResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return &ApiKeyCredentials{
+		Key:        result.ApiKey.Key,
+		Secret:     result.ApiKey.Secret,
+		Passphrase: result.ApiKey.Passphrase,
+	}, nil
+}
+
+// GetApiKeys 查询 API Key 列表
+// see https://docs.dydx.exchange/?json#get-api-keys
+func (p EthPrivate) GetApiKeys(ethereumAddress string) (*types.ApiKeysResponse, error) {
+	res, err := p.request(http.MethodGet, "api-keys", ethereumAddress)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.ApiKeysResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteApiKey 删除 API Key
+// see https://docs.dydx.exchange/?json#delete-api-key
+func (p EthPrivate) DeleteApiKey(apiKey, ethereumAddress string) error {
+	params := url.Values{}
+	params.Add("apiKey", apiKey)
+	_, err := p.request(http.MethodDelete, common.GenerateQueryPath("api-keys", params), ethereumAddress)
+	return err
+}
+
+// request 签名内容为 method + requestPath + body + timestamp, 这些接口没有请求参数, body 固定为 "{}"
+func (p EthPrivate) request(method, endpoint, ethereumAddress string) ([]byte, error) {
+	if ethereumAddress == "" {
+		ethereumAddress = p.DefaultAddress
+	}
+	isoTimestamp := common.GenerateNowISO()
+	requestPath := fmt.Sprintf("/v3/%s", endpoint)
+	signature, err := p.Singer.SignWithContext(p.ctx, ethereumAddress, method, requestPath, emptyBody, isoTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{
+		"DYDX-SIGNATURE":        signature,
+		"DYDX-TIMESTAMP":        isoTimestamp,
+		"DYDX-ETHEREUM-ADDRESS": ethereumAddress,
+	}
+	// api-keys 的响应含有 secret 和 passphrase, 不记录响应体
+	return doRequest(p.ctx, p.HttpClient, p.Host, p.Logger, method, requestPath, headers, emptyBody, false)
+}
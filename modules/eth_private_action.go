@@ -0,0 +1,63 @@
+package modules
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	solsha3 "github.com/miguelmota/go-solidity-sha3"
+	"github.com/verichenn/dydx-v3-go/common"
+)
+
+var (
+	Eip712EthPrivateActionStruct = []map[string]string{
+		{"type": "string", "name": "method"},
+		{"type": "string", "name": "requestPath"},
+		{"type": "string", "name": "body"},
+		{"type": "string", "name": "timestamp"},
+	}
+	Eip712EthPrivateActionStructString = "dYdX(string method,string requestPath,string body,string timestamp)"
+)
+
+// SignEthPrivateAction 以太坊私钥鉴权接口(如 /v3/api-keys)的 EIP-712 签名
+type SignEthPrivateAction struct {
+	Signer    EthSigner
+	NetworkId int
+}
+
+func NewEthPrivateSigner(signer EthSigner, networkId int) *SignEthPrivateAction {
+	return &SignEthPrivateAction{signer, networkId}
+}
+
+func (a *SignEthPrivateAction) Sign(signerAddress, method, requestPath, body, timestamp string) string {
+	signature, err := a.SignWithContext(context.Background(), signerAddress, method, requestPath, body, timestamp)
+	if err != nil {
+		panic(err)
+	}
+	return signature
+}
+
+// SignWithContext 同 Sign, 使用 web3 节点签名时 ctx 结束即返回 ctx.Err()
+func (a *SignEthPrivateAction) SignWithContext(ctx context.Context, signerAddress, method, requestPath, body, timestamp string) (string, error) {
+	message := map[string]interface{}{
+		"method":      method,
+		"requestPath": requestPath,
+		"body":        body,
+		"timestamp":   timestamp,
+	}
+	eip712Message := getEIP712Message(a.NetworkId, Eip712StructName, Eip712EthPrivateActionStruct, message)
+	messageHash := a.GetHash(method, requestPath, body, timestamp)
+	return a.Signer.sign(contextOrBackground(ctx), eip712Message, messageHash, signerAddress)
+}
+
+func (a *SignEthPrivateAction) GetHash(method, requestPath, body, timestamp string) string {
+	structHash := solsha3.SoliditySHA3(
+		[]string{"bytes32", "bytes32", "bytes32", "bytes32", "bytes32"},
+		[]interface{}{
+			common.HashString(Eip712EthPrivateActionStructString),
+			common.HashString(method),
+			common.HashString(requestPath),
+			common.HashString(body),
+			common.HashString(timestamp),
+		},
+	)
+	return getEip712Hash(a.NetworkId, hexutil.Encode(structHash))
+}
@@ -1,30 +1,50 @@
 package modules
 
 import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/umbracle/go-web3/jsonrpc"
 	"github.com/verichenn/dydx-v3-go/common"
+	"strings"
 )
 
 type EthSigner interface {
-	sign(eip712Message map[string]interface{}, messageHash, optSingerAddress string) string
+	sign(ctx context.Context, eip712Message map[string]interface{}, messageHash, optSingerAddress string) (string, error)
 }
 
 type EthWeb3Signer struct {
 	Web3 *jsonrpc.Client
 }
 
-func (web3Singer *EthWeb3Signer) sign(eip712Message map[string]interface{}, messageHash, address string) string {
-	rawSignature := signTypedData(eip712Message, web3Singer, address)
-	return common.CreateTypedSignature(rawSignature, common.SignatureTypeNoPrepend)
+func (web3Singer *EthWeb3Signer) sign(ctx context.Context, eip712Message map[string]interface{}, messageHash, address string) (string, error) {
+	rawSignature, err := signTypedData(ctx, eip712Message, web3Singer, address)
+	if err != nil {
+		return "", err
+	}
+	return common.CreateTypedSignature(rawSignature, common.SignatureTypeNoPrepend), nil
 }
 
 //https://github.com/dydxprotocol/dydx-v3-python/issues/62
-func signTypedData(eip712Message map[string]interface{}, web3Singer *EthWeb3Signer, address string) string {
-	var out string
-	if err := web3Singer.Web3.Call("eth_signTypedData", &out, address, eip712Message); err != nil {
-		panic(err)
+// jsonrpc.Client 不支持 context, ctx 结束时直接返回, 节点上的调用不会被中断
+func signTypedData(ctx context.Context, eip712Message map[string]interface{}, web3Singer *EthWeb3Signer, address string) (string, error) {
+	type result struct {
+		out string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var out string
+		err := web3Singer.Web3.Call("eth_signTypedData", &out, address, eip712Message)
+		done <- result{out, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-done:
+		return res.out, res.err
 	}
-	return out
 }
 
 type EthKeySinger struct {
@@ -32,6 +52,38 @@ type EthKeySinger struct {
 	PrivateKey string
 }
 
-func (keySinger EthKeySinger) sign(eip712Message map[string]interface{}, messageHash, optSingerAddress string) string {
-	panic("implement me")
+// NewEthKeySinger 根据以太坊私钥创建签名器, 并推导出对应的地址
+func NewEthKeySinger(privateKey string) (*EthKeySinger, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ethereum private key: %w", err)
+	}
+	return &EthKeySinger{
+		Address:    crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		PrivateKey: privateKey,
+	}, nil
+}
+
+// sign 使用以太坊私钥直接对 EIP-712 消息哈希签名, 无需 web3 节点
+func (keySinger EthKeySinger) sign(ctx context.Context, eip712Message map[string]interface{}, messageHash, optSingerAddress string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(keySinger.PrivateKey, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("invalid ethereum private key: %w", err)
+	}
+	address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	if optSingerAddress != "" && !strings.EqualFold(optSingerAddress, address) {
+		return "", fmt.Errorf("signer address %s does not match private key address %s", optSingerAddress, address)
+	}
+	hash, err := hexutil.Decode(messageHash)
+	if err != nil {
+		return "", err
+	}
+	rawSignature, err := crypto.Sign(hash, privateKey)
+	if err != nil {
+		return "", err
+	}
+	return common.CreateTypedSignature(hexutil.Encode(rawSignature), common.SignatureTypeNoPrepend), nil
 }
@@ -0,0 +1,99 @@
+package modules
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultApiTimeout = 5 * time.Second
+
+// defaultHttpClient 未注入 HttpClient 时各模块共用的客户端
+var defaultHttpClient = NewHttpClient(nil, defaultApiTimeout)
+
+// NewTransport 返回启用长连接复用的 Transport, 同一 host 保持较多空闲连接以降低下单延迟
+func NewTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   32,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// NewHttpClient transport 为 nil 时使用 NewTransport()
+func NewHttpClient(transport http.RoundTripper, timeout time.Duration) *http.Client {
+	if transport == nil {
+		transport = NewTransport()
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+}
+
+// doRequest ctx 为 nil 时使用 context.Background(), httpClient 为 nil 时使用 defaultHttpClient
+// doRequest 发送请求并返回响应体; 响应中含有 API Key 密钥等凭证时 logBody 传 false, 日志中只记录响应长度
+func doRequest(ctx context.Context, httpClient *http.Client, host string, logger *log.Logger, method, requestPath string, headers map[string]string, data string, logBody bool) ([]byte, error) {
+	resp, err := execute(ctx, httpClient, host, method, requestPath, headers, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, requestPath, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(resp.Body)
+		logger.Printf("uri:%s, code: %d, err msg:%s", requestPath, resp.StatusCode, buf.String())
+		return nil, newAPIError(method, requestPath, resp.StatusCode, buf.Bytes())
+	}
+	responseBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: read response body: %w", method, requestPath, err)
+	}
+	if logBody {
+		logger.Printf("uri:%s,response body:%s", requestPath, responseBody)
+	} else {
+		logger.Printf("uri:%s,response body:<redacted %d bytes>", requestPath, len(responseBody))
+	}
+	return responseBody, nil
+}
+
+func execute(ctx context.Context, httpClient *http.Client, host, method, requestPath string, headers map[string]string, data string) (*http.Response, error) {
+	requestPath = fmt.Sprintf("%s%s", host, requestPath)
+	req, err := http.NewRequestWithContext(contextOrBackground(ctx), method, requestPath, strings.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	for key, val := range headers {
+		req.Header.Add(key, val)
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("User-Agent", "dydx/go")
+
+	if httpClient == nil {
+		httpClient = defaultHttpClient
+	}
+	return httpClient.Do(req)
+}
+
+func contextOrBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
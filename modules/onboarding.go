@@ -1,15 +1,22 @@
 package modules
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
 	solsha3 "github.com/miguelmota/go-solidity-sha3"
 	"github.com/verichenn/dydx-v3-go/common"
+	"github.com/verichenn/dydx-v3-go/types"
 	"log"
 	"math/big"
+	"net/http"
 	"strings"
 )
 
@@ -21,24 +28,52 @@ type OnBoarding struct {
 	StarkPublicKey            string
 	StarkPublicKeyYCoordinate string
 	Singer                    *SignOnboardingAction
+	HttpClient                *http.Client
 	Logger                    *log.Logger
+	ctx                       context.Context
+}
+
+// WithContext 返回使用 ctx 签名及发起请求的 OnBoarding
+func (board OnBoarding) WithContext(ctx context.Context) *OnBoarding {
+	board.ctx = ctx
+	return &board
 }
 
 type ApiKeyCredentials struct {
-	Key        string
-	Secret     string
-	Passphrase string
+	Key        string `json:"key"`
+	Secret     string `json:"secret"`
+	Passphrase string `json:"passphrase"`
+}
+
+type ApiOnboarding struct {
+	StarkKey                string `json:"starkKey"`
+	StarkKeyYCoordinate     string `json:"starkKeyYCoordinate"`
+	EthereumAddress         string `json:"ethereumAddress"`
+	ReferredByAffiliateLink string `json:"referredByAffiliateLink,omitempty"`
+	Country                 string `json:"country,omitempty"`
+}
+
+// Sign 使用 API Secret 对请求做 HMAC-SHA256 签名, 用于 DYDX-SIGNATURE 请求头及 websocket 账户频道鉴权
+func (c *ApiKeyCredentials) Sign(requestPath, method, isoTimestamp, body string) string {
+	message := fmt.Sprintf("%s%s%s%s", isoTimestamp, method, requestPath, body)
+	secret, _ := base64.URLEncoding.DecodeString(c.Secret)
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(message))
+	return base64.URLEncoding.EncodeToString(h.Sum(nil))
 }
 
 func (board OnBoarding) RecoverDefaultApiCredentials(ethereumAddress string) *ApiKeyCredentials {
-	signature := board.Singer.Sign(ethereumAddress, map[string]interface{}{"action": common.OffChainOnboardingAction})
+	signature, err := board.Singer.SignWithContext(board.ctx, ethereumAddress, map[string]interface{}{"action": common.OffChainOnboardingAction})
+	if err != nil {
+		panic(err)
+	}
 	rHex := signature[2:66]
-	rInt, _ := math.MaxBig256.SetString(rHex, 16)
+	rInt, _ := new(big.Int).SetString(rHex, 16)
 
 	hashedRBytes := solsha3.SoliditySHA3([]string{"uint256"}, rInt.String())
 	secretBytes := hashedRBytes[:30]
 	sHex := signature[66:130]
-	sInt, _ := math.MaxBig256.SetString(sHex, 16)
+	sInt, _ := new(big.Int).SetString(sHex, 16)
 
 	hashedSBytes := solsha3.SoliditySHA3([]string{"uint256"}, sInt.String())
 	keyBytes := hashedSBytes[:16]
@@ -59,18 +94,96 @@ func (board OnBoarding) RecoverDefaultApiCredentials(ethereumAddress string) *Ap
 	}
 }
 
+type StarkKeyPair struct {
+	PrivateKey           string
+	PublicKey            string
+	PublicKeyYCoordinate string
+}
+
+// DeriveStarkKey 由以太坊签名推导 STARK 私钥
 func (board OnBoarding) DeriveStarkKey(ethereumAddress string) string {
-	signature := board.Singer.Sign(ethereumAddress, map[string]interface{}{"action": common.OffChainKeyDerivationAction})
-	sig, _ := new(big.Int).SetString(signature, 0)
+	privateKey, err := board.deriveStarkPrivateKey(ethereumAddress)
+	if err != nil {
+		panic(err)
+	}
+	return privateKey
+}
+
+// DeriveStarkKeyPair 由以太坊签名推导完整的 STARK 密钥对, 与官方 TypeScript/Python 客户端结果一致
+func (board OnBoarding) DeriveStarkKeyPair(ethereumAddress string) (*StarkKeyPair, error) {
+	privateKey, err := board.deriveStarkPrivateKey(ethereumAddress)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, publicKeyY, err := common.StarkPrivateKeyToPublicKeyPair(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &StarkKeyPair{
+		PrivateKey:           privateKey,
+		PublicKey:            publicKey,
+		PublicKeyYCoordinate: publicKeyY,
+	}, nil
+}
 
-	sha3 := solsha3.SoliditySHA3([]string{"uint256"}, sig.String())
-	hashedSignature := hexutil.Encode(sha3)
+// deriveStarkPrivateKey 对完整的签名(含类型字节, 共 66 字节)做 keccak256, 结果右移 5 位
+func (board OnBoarding) deriveStarkPrivateKey(ethereumAddress string) (string, error) {
+	signature, err := board.Singer.SignWithContext(board.ctx, ethereumAddress, map[string]interface{}{"action": common.OffChainKeyDerivationAction})
+	if err != nil {
+		return "", err
+	}
+	// 直接解码十六进制, 经 big.Int 转换会丢掉签名开头的 0x00 字节
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return "", err
+	}
 
-	privateKey, _ := new(big.Int).SetString(hashedSignature, 0)
+	hashedSignature := crypto.Keccak256(sig)
+	privateKey := new(big.Int).SetBytes(hashedSignature)
 	privateKey = new(big.Int).Rsh(privateKey, 5)
-	return fmt.Sprintf("0x%s", privateKey.Text(16))
+	return fmt.Sprintf("0x%s", privateKey.Text(16)), nil
+}
+
+// CreateUser 注册新用户, StarkKey 及以太坊地址为空时使用 OnBoarding 上配置的默认值
+// see https://docs.dydx.exchange/?json#onboarding
+func (board OnBoarding) CreateUser(input *ApiOnboarding) (*types.CreateUserResponse, error) {
+	if input.StarkKey == "" {
+		input.StarkKey = board.StarkPublicKey
+	}
+	if input.StarkKeyYCoordinate == "" {
+		input.StarkKeyYCoordinate = board.StarkPublicKeyYCoordinate
+	}
+	if input.EthereumAddress == "" {
+		input.EthereumAddress = board.EthAddress
+	}
+	if input.StarkKey == "" || input.StarkKeyYCoordinate == "" {
+		return nil, errors.New("stark public key and y coordinate are required")
+	}
+	res, err := board.post("onboarding", input.EthereumAddress, input)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.CreateUserResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
-func (board OnBoarding) sign(signerAddress, action string) string {
-	return board.Singer.Sign(signerAddress, map[string]interface{}{"action": action})
+func (board OnBoarding) post(endpoint, ethereumAddress string, data interface{}) ([]byte, error) {
+	marshalData, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := board.Singer.SignWithContext(board.ctx, ethereumAddress, map[string]interface{}{"action": common.OffChainOnboardingAction})
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{
+		"DYDX-SIGNATURE":        signature,
+		"DYDX-ETHEREUM-ADDRESS": ethereumAddress,
+	}
+	requestPath := fmt.Sprintf("/v3/%s", endpoint)
+	// onboarding 的响应含有新建 API Key 的 secret 和 passphrase, 不记录响应体
+	return doRequest(board.ctx, board.HttpClient, board.Host, board.Logger, http.MethodPost, requestPath, headers, string(marshalData), false)
 }
@@ -0,0 +1,55 @@
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"github.com/verichenn/dydx-v3-go/common"
+	"time"
+)
+
+// ErrPageCursorStuck 同一时间戳的记录超过单页上限, 以时间为游标无法继续翻页
+var ErrPageCursorStuck = errors.New("dydx: more records share one timestamp than fit in a page")
+
+type pageRecord struct {
+	Key string
+	At  time.Time
+}
+
+// pageBackward 从 before(为空则从最新)开始按时间向前翻页, 对每条不早于 cutoff 的新记录调用 collect(i), i 为其在本页中的下标.
+// 游标包含边界, 上一页末尾同一时间戳的记录会再次返回, 以 Key 去重.
+// 整页都是已见过的记录时将页大小放大到 MaxPageLimit 重试, 仍无进展则返回 ErrPageCursorStuck, 不返回被截断的结果
+func pageBackward(limit int, before string, cutoff time.Time, fetch func(limit int, before string) ([]pageRecord, error), collect func(i int)) error {
+	if limit <= 0 || limit > common.MaxPageLimit {
+		limit = common.MaxPageLimit
+	}
+	seen := map[string]bool{}
+	for {
+		records, err := fetch(limit, before)
+		if err != nil {
+			return err
+		}
+		added := 0
+		for i, record := range records {
+			if record.At.Before(cutoff) {
+				return nil
+			}
+			if seen[record.Key] {
+				continue
+			}
+			seen[record.Key] = true
+			collect(i)
+			added++
+		}
+		if len(records) < limit {
+			return nil
+		}
+		if added == 0 {
+			if limit < common.MaxPageLimit {
+				limit = common.MaxPageLimit
+				continue
+			}
+			return fmt.Errorf("%w: %s", ErrPageCursorStuck, before)
+		}
+		before = records[len(records)-1].At.UTC().Format("2006-01-02T15:04:05.999Z")
+	}
+}
@@ -1,20 +1,17 @@
 package modules
 
 import (
-	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/verichenn/dydx-v3-go/common"
 	"github.com/verichenn/dydx-v3-go/types"
 	"github.com/yanue/starkex"
-	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -24,8 +21,25 @@ type Private struct {
 	NetworkId         int
 	StarkPrivateKey   string
 	DefaultAddress    string
+	AccountNumber     int
 	ApiKeyCredentials *ApiKeyCredentials
+	RetryPolicy       *RetryPolicy
+	HttpClient        *http.Client
 	Logger            *log.Logger
+	ctx               context.Context
+}
+
+// WithContext 返回使用 ctx 发起请求的 Private, 可用于取消请求或设置单次调用的超时
+func (p Private) WithContext(ctx context.Context) *Private {
+	p.ctx = ctx
+	return &p
+}
+
+// WithAccountNumber 返回操作同一钱包下指定 account number 账户的 Private.
+// AccountNumber 仅用于 GetAccount 计算账户 id, 其余私有接口由服务端按 API Key 确定账户, 不受其影响
+func (p Private) WithAccountNumber(accountNumber int) *Private {
+	p.AccountNumber = accountNumber
+	return &p
 }
 
 type ApiBaseOrder struct {
@@ -49,14 +63,115 @@ type ApiOrder struct {
 	TrailingPercent string `json:"trailingPercent,omitempty"`
 }
 
+type ApiWithdrawal struct {
+	ApiBaseOrder
+	Amount   string `json:"amount"`
+	Asset    string `json:"asset"`
+	ClientId string `json:"clientId"`
+}
+
+type ApiFastWithdrawal struct {
+	ApiBaseOrder
+	CreditAsset      string `json:"creditAsset"`
+	CreditAmount     string `json:"creditAmount"`
+	DebitAmount      string `json:"debitAmount"`
+	ToAddress        string `json:"toAddress"`
+	LpPositionId     int64  `json:"lpPositionId,string"`
+	LpStarkPublicKey string `json:"-"`
+	ClientId         string `json:"clientId"`
+}
+
+type ApiTransfer struct {
+	ApiBaseOrder
+	Amount             string `json:"amount"`
+	ReceiverAccountId  string `json:"receiverAccountId"`
+	ReceiverPublicKey  string `json:"-"`
+	ReceiverPositionId int64  `json:"-"`
+	ClientId           string `json:"clientId"`
+}
+
+// ApiUser 更新用户信息的参数, UserData 以 JSON 字符串形式提交
+type ApiUser struct {
+	UserData          map[string]interface{} `json:"-"`
+	Email             string                 `json:"email,omitempty"`
+	Username          string                 `json:"username,omitempty"`
+	IsSharingUsername *bool                  `json:"isSharingUsername,omitempty"`
+	IsSharingAddress  *bool                  `json:"isSharingAddress,omitempty"`
+	Country           string                 `json:"country,omitempty"`
+}
+
+// GetUser 查询当前用户
+// see https://docs.dydx.exchange/?json#get-user
+func (p Private) GetUser() (*types.UserResponse, error) {
+	res, err := p.get("users", nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.UserResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateUser 更新用户信息
+// see https://docs.dydx.exchange/?json#update-user
+func (p Private) UpdateUser(input *ApiUser) (*types.UserResponse, error) {
+	userData := input.UserData
+	if userData == nil {
+		userData = map[string]interface{}{}
+	}
+	userDataJson, err := json.Marshal(userData)
+	if err != nil {
+		return nil, err
+	}
+	body := struct {
+		*ApiUser
+		UserData string `json:"userData"`
+	}{input, string(userDataJson)}
+	res, err := p.put("users", body)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.UserResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SendVerificationEmail 发送邮箱验证邮件, 用户需先通过 UpdateUser 设置邮箱
+// see https://docs.dydx.exchange/?json#send-verification-email
+func (p Private) SendVerificationEmail() error {
+	_, err := p.put("emails/send-verification-email", struct{}{})
+	return err
+}
+
+// GetPrivateProfile 查询当前用户的个人资料
+// see https://docs.dydx.exchange/?json#get-private-profile
+func (p Private) GetPrivateProfile() (*types.PrivateProfileResponse, error) {
+	res, err := p.get("profile/private", nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.PrivateProfileResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // GetAccount 查询账户
 // see https://docs.dydx.exchange/?json#get-account
 func (p Private) GetAccount(ethereumAddress string) (*types.AccountResponse, error) {
 	if ethereumAddress == "" {
 		ethereumAddress = p.DefaultAddress
 	}
-	uri := fmt.Sprintf("accounts/%s", common.GetAccountId(ethereumAddress))
-	res, _ := p.get(uri, nil)
+	uri := fmt.Sprintf("accounts/%s", common.GetAccountIdByNumber(ethereumAddress, p.AccountNumber))
+	res, err := p.get(uri, nil)
+	if err != nil {
+		return nil, err
+	}
 	accountResponse := &types.AccountResponse{}
 	if err := json.Unmarshal(res, accountResponse); err != nil {
 		return nil, err
@@ -64,9 +179,27 @@ func (p Private) GetAccount(ethereumAddress string) (*types.AccountResponse, err
 	return accountResponse, nil
 }
 
-// CreateOrder 创建订单
+// GetAccounts 查询当前用户的全部账户
+// see https://docs.dydx.exchange/?json#get-accounts
+func (p Private) GetAccounts() (*types.AccountsResponse, error) {
+	res, err := p.get("accounts", nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.AccountsResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateOrder 创建订单, ClientId 为空时随机生成.
+// 按 RetryPolicy 重试时复用同一 ClientId 与签名, 若服务端以 clientId 重复拒绝, 说明之前的请求已成功, 返回已存在的订单
 // see https://docs.dydx.exchange/?json#create-a-new-order
 func (p Private) CreateOrder(input *ApiOrder, positionId int64) (*types.OrderResponse, error) {
+	if input.ClientId == "" {
+		input.ClientId = common.RandomClientId()
+	}
 	orderSignParam := starkex.OrderSignParam{
 		NetworkId:  p.NetworkId,
 		PositionId: positionId,
@@ -78,13 +211,23 @@ func (p Private) CreateOrder(input *ApiOrder, positionId int64) (*types.OrderRes
 		ClientId:   input.ClientId,
 		Expiration: input.Expiration,
 	}
-	signature, err := starkex.OrderSign(p.StarkPrivateKey[2:], orderSignParam)
-
+	signature, err := starkex.OrderSign(strings.TrimPrefix(p.StarkPrivateKey, "0x"), orderSignParam)
 	if err != nil {
-		return nil, errors.New("sign error")
+		return nil, fmt.Errorf("sign order error: %w", err)
 	}
 	input.Signature = signature
-	res, _ := p.post("orders", input)
+	marshalData, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("marshal orders request error: %w", err)
+	}
+	retried := false
+	res, err := p.requestWithRetry(http.MethodPost, "orders", string(marshalData), p.RetryPolicy.attempts(http.MethodPost), &retried)
+	if err != nil {
+		if retried && isDuplicateClientId(err) {
+			return p.GetOrderByClientId(input.ClientId)
+		}
+		return nil, err
+	}
 
 	orderResponse := &types.OrderResponse{}
 	if err = json.Unmarshal(res, orderResponse); err != nil {
@@ -93,6 +236,116 @@ func (p Private) CreateOrder(input *ApiOrder, positionId int64) (*types.OrderRes
 	return orderResponse, nil
 }
 
+// CreateWithdrawal 发起慢速提现, 使用 STARK 私钥对提现消息签名; ClientId 为空时随机生成, Asset 为空时使用 USDC
+// see https://docs.dydx.exchange/?json#create-withdrawal
+func (p Private) CreateWithdrawal(input *ApiWithdrawal, positionId int64) (*types.WithdrawalResponse, error) {
+	if input.ClientId == "" {
+		input.ClientId = common.RandomClientId()
+	}
+	if input.Asset == "" {
+		input.Asset = common.AssetUsdc
+	}
+	withdrawSignParam := starkex.WithdrawSignParam{
+		NetworkId:   p.NetworkId,
+		PositionId:  positionId,
+		HumanAmount: input.Amount,
+		ClientId:    input.ClientId,
+		Expiration:  input.Expiration,
+	}
+	signature, err := starkex.WithdrawSign(strings.TrimPrefix(p.StarkPrivateKey, "0x"), withdrawSignParam)
+	if err != nil {
+		return nil, fmt.Errorf("sign withdrawal error: %w", err)
+	}
+	input.Signature = signature
+	res, err := p.post("withdrawals", input)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.WithdrawalResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateFastWithdrawal 发起快速提现, 以条件转账的形式将 DebitAmount 转给流动性提供者,
+// 条件为其在 L1 向 ToAddress 转出 CreditAmount; ClientId 为空时随机生成, CreditAsset 为空时使用 USDC
+// see https://docs.dydx.exchange/?json#create-fast-withdrawal
+func (p Private) CreateFastWithdrawal(input *ApiFastWithdrawal, positionId int64) (*types.WithdrawalResponse, error) {
+	if input.ClientId == "" {
+		input.ClientId = common.RandomClientId()
+	}
+	if input.CreditAsset == "" {
+		input.CreditAsset = common.AssetUsdc
+	}
+	transferSignParam := starkex.TransferSignParam{
+		NetworkId:          p.NetworkId,
+		SenderPositionId:   positionId,
+		ReceiverPositionId: input.LpPositionId,
+		ReceiverPublicKey:  input.LpStarkPublicKey,
+		ReceiverAddress:    input.ToAddress,
+		CreditAmount:       input.CreditAmount,
+		DebitAmount:        input.DebitAmount,
+		Expiration:         input.Expiration,
+		ClientId:           input.ClientId,
+	}
+	signature, err := starkex.TransferSign(strings.TrimPrefix(p.StarkPrivateKey, "0x"), transferSignParam)
+	if err != nil {
+		return nil, fmt.Errorf("sign fast withdrawal error: %w", err)
+	}
+	input.Signature = signature
+	res, err := p.post("fast-withdrawals", input)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.WithdrawalResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateTransfer 向其他账户转账, 使用 STARK 私钥对转账签名; ClientId 为空时随机生成
+// see https://docs.dydx.exchange/?json#create-transfer
+func (p Private) CreateTransfer(input *ApiTransfer, positionId int64) (*types.TransferResponse, error) {
+	switch {
+	case input.ReceiverAccountId == "":
+		return nil, errors.New("receiver account id is required")
+	case input.ReceiverPublicKey == "":
+		return nil, errors.New("receiver public key is required")
+	case input.ReceiverPositionId <= 0:
+		return nil, errors.New("receiver position id is required")
+	case input.ReceiverPositionId == positionId:
+		return nil, errors.New("receiver position id must differ from sender position id")
+	}
+	if input.ClientId == "" {
+		input.ClientId = common.RandomClientId()
+	}
+	transferParam := common.StarkTransferParam{
+		NetworkId:          p.NetworkId,
+		SenderPositionId:   positionId,
+		ReceiverPositionId: input.ReceiverPositionId,
+		ReceiverPublicKey:  input.ReceiverPublicKey,
+		HumanAmount:        input.Amount,
+		ClientId:           input.ClientId,
+		Expiration:         input.Expiration,
+	}
+	signature, err := common.StarkTransferSign(p.StarkPrivateKey, transferParam)
+	if err != nil {
+		return nil, fmt.Errorf("sign transfer error: %w", err)
+	}
+	input.Signature = signature
+	res, err := p.post("transfers", input)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.TransferResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // GetPositions 查询持仓
 // see https://docs.dydx.exchange/?json#get-positions
 func (p Private) GetPositions(market string) (*types.PositionResponse, error) {
@@ -102,11 +355,11 @@ func (p Private) GetPositions(market string) (*types.PositionResponse, error) {
 	}
 	res, err := p.get("positions", params)
 	if err != nil {
-		return nil, errors.New("request error")
+		return nil, err
 	}
 	position := &types.PositionResponse{}
-	if err = json.Unmarshal(res, &position); err != nil {
-		return nil, errors.New("json parser error")
+	if err = json.Unmarshal(res, position); err != nil {
+		return nil, err
 	}
 	return position, nil
 }
@@ -115,14 +368,14 @@ func (p Private) GetPositions(market string) (*types.PositionResponse, error) {
 // see https://docs.dydx.exchange/?json#get-orders
 func (p Private) GetOrders(input *types.OrderQueryParam) (*types.OrderListResponse, error) {
 	data, err := p.get("orders", input.ToParams())
+	if err != nil {
+		return nil, err
+	}
 	result := &types.OrderListResponse{}
-	if err == nil {
-		if err := json.Unmarshal(data, &result); err != nil {
-			return nil, err
-		}
-		return result, nil
+	if err = json.Unmarshal(data, result); err != nil {
+		return nil, err
 	}
-	return nil, err
+	return result, nil
 }
 
 // CancelOrder 取消订单
@@ -139,6 +392,25 @@ func (p Private) CancelOrder(orderId string) (*types.CancelOrderResponse, error)
 	return result, nil
 }
 
+// CancelOrderByClientId 按客户端订单 id 取消订单
+func (p Private) CancelOrderByClientId(clientId string) (*types.CancelOrderResponse, error) {
+	order, err := p.GetOrderByClientId(clientId)
+	if err != nil {
+		return nil, err
+	}
+	return p.CancelOrder(order.Order.ID)
+}
+
+// ReplaceOrderByClientId 下新单并原子地取消客户端订单 id 为 clientId 的旧单
+func (p Private) ReplaceOrderByClientId(clientId string, input *ApiOrder, positionId int64) (*types.OrderResponse, error) {
+	order, err := p.GetOrderByClientId(clientId)
+	if err != nil {
+		return nil, err
+	}
+	input.CancelId = order.Order.ID
+	return p.CreateOrder(input, positionId)
+}
+
 func (p Private) CancelOrders(market string) (*types.CancelOrdersResponse, error) {
 	values := url.Values{}
 	if market != "" {
@@ -155,85 +427,290 @@ func (p Private) CancelOrders(market string) (*types.CancelOrdersResponse, error
 	return result, nil
 }
 
-// GetOrderById 查询订单
-// see https://docs.dydx.exchange/?json#get-order-by-id
-func (p Private) GetOrderById(orderId string) (*types.OrderResponse, error) {
-	res, err := p.get("orders/"+orderId, nil)
+// GetActiveOrders 查询活跃订单, 与 GetOrders 分开限频
+// see https://docs.dydx.exchange/?json#get-active-orders
+func (p Private) GetActiveOrders(input *types.ActiveOrderQueryParam) (*types.ActiveOrdersResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	res, err := p.get("active-orders", input.ToParams())
+	if err != nil {
+		return nil, err
+	}
+	result := &types.ActiveOrdersResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-	orderResponse := &types.OrderResponse{}
-	if err == nil {
-		if err := json.Unmarshal(res, &orderResponse); err != nil {
+// CancelActiveOrders 取消活跃订单, 与 CancelOrders 分开限频
+// see https://docs.dydx.exchange/?json#cancel-active-orders
+func (p Private) CancelActiveOrders(input *types.ActiveOrderQueryParam) (*types.CancelActiveOrdersResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	res, err := p.delete("active-orders", input.ToParams())
+	if err != nil {
+		return nil, err
+	}
+	result := &types.CancelActiveOrdersResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetFills 查询成交明细
+// see https://docs.dydx.exchange/?json#get-fills
+func (p Private) GetFills(input *types.FillQueryParam) (*types.FillsResponse, error) {
+	res, err := p.get("fills", input.ToParams())
+	if err != nil {
+		return nil, err
+	}
+	result := &types.FillsResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetFillsSince 从 input.CreatedBeforeOrAt(为空则从最新)开始向前翻页, 返回创建时间不早于 cutoff 的全部成交.
+// input.Limit 超过 MaxPageLimit 时按 MaxPageLimit 翻页, 同一时间戳的成交超过一页时返回 ErrPageCursorStuck
+func (p Private) GetFillsSince(input *types.FillQueryParam, cutoff time.Time) ([]types.Fill, error) {
+	var fills, page []types.Fill
+	fetch := func(limit int, before string) ([]pageRecord, error) {
+		query := *input
+		query.Limit, query.CreatedBeforeOrAt = limit, before
+		data, err := p.GetFills(&query)
+		if err != nil {
 			return nil, err
 		}
-		return orderResponse, nil
+		page = data.Fills
+		records := make([]pageRecord, len(page))
+		for i, fill := range page {
+			records[i] = pageRecord{Key: fill.ID, At: fill.CreatedAt}
+		}
+		return records, nil
+	}
+	if err := pageBackward(input.Limit, input.CreatedBeforeOrAt, cutoff, fetch, func(i int) {
+		fills = append(fills, page[i])
+	}); err != nil {
+		return nil, err
 	}
-	return nil, err
+	return fills, nil
 }
 
-func (p Private) get(endpoint string, params url.Values) ([]byte, error) {
-	return p.request(http.MethodGet, common.GenerateQueryPath(endpoint, params), "")
+// GetFundingPayments 查询资金费支付记录
+// see https://docs.dydx.exchange/?json#get-funding-payment
+func (p Private) GetFundingPayments(input *types.FundingPaymentQueryParam) (*types.FundingPaymentsResponse, error) {
+	res, err := p.get("funding", input.ToParams())
+	if err != nil {
+		return nil, err
+	}
+	result := &types.FundingPaymentsResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
-func (p Private) post(endpoint string, data interface{}) ([]byte, error) {
-	marshalData, _ := json.Marshal(data)
-	return p.request(http.MethodPost, endpoint, string(marshalData))
+// GetFundingPaymentsSince 从 input.EffectiveBeforeOrAt(为空则从最新)开始向前翻页, 返回生效时间不早于 cutoff 的全部资金费记录.
+// 各市场在同一整点结算, 同一时间戳的记录超过一页时返回 ErrPageCursorStuck
+func (p Private) GetFundingPaymentsSince(input *types.FundingPaymentQueryParam, cutoff time.Time) ([]types.FundingPayment, error) {
+	var payments, page []types.FundingPayment
+	fetch := func(limit int, before string) ([]pageRecord, error) {
+		query := *input
+		query.Limit, query.EffectiveBeforeOrAt = limit, before
+		data, err := p.GetFundingPayments(&query)
+		if err != nil {
+			return nil, err
+		}
+		page = data.FundingPayments
+		records := make([]pageRecord, len(page))
+		for i, payment := range page {
+			// 资金费记录没有 id, 以 market + effectiveAt 去重
+			records[i] = pageRecord{Key: payment.Market + payment.EffectiveAt.String(), At: payment.EffectiveAt}
+		}
+		return records, nil
+	}
+	if err := pageBackward(input.Limit, input.EffectiveBeforeOrAt, cutoff, fetch, func(i int) {
+		payments = append(payments, page[i])
+	}); err != nil {
+		return nil, err
+	}
+	return payments, nil
 }
 
-func (p Private) delete(endpoint string, params url.Values) ([]byte, error) {
-	return p.request(http.MethodGet, common.GenerateQueryPath(endpoint, params), "")
+// GetHistoricalPnl 查询账户每日盈亏
+// see https://docs.dydx.exchange/?json#get-historical-pnl-ticks
+func (p Private) GetHistoricalPnl(input *types.HistoricalPnlQueryParam) (*types.HistoricalPnlResponse, error) {
+	res, err := p.get("historical-pnl", input.ToParams())
+	if err != nil {
+		return nil, err
+	}
+	result := &types.HistoricalPnlResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
-func (p Private) request(method, endpoint string, data string) ([]byte, error) {
-	isoTimestamp := generateNowISO()
-	requestPath := fmt.Sprintf("/v3/%s", endpoint)
-	headers := map[string]string{
-		"DYDX-SIGNATURE":  p.Sign(requestPath, method, isoTimestamp, data),
-		"DYDX-API-KEY":    p.ApiKeyCredentials.Key,
-		"DYDX-TIMESTAMP":  isoTimestamp,
-		"DYDX-PASSPHRASE": p.ApiKeyCredentials.Passphrase,
+// GetTradingRewards 查询交易奖励, epoch 小于 0 时查询当前 epoch
+// see https://docs.dydx.exchange/?json#get-trading-rewards
+func (p Private) GetTradingRewards(epoch int) (*types.TradingRewardsResponse, error) {
+	res, err := p.get("rewards/weight", epochParams(epoch))
+	if err != nil {
+		return nil, err
+	}
+	result := &types.TradingRewardsResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetLiquidityProviderRewards 查询流动性提供者奖励, epoch 小于 0 时查询当前 epoch
+// see https://docs.dydx.exchange/?json#get-liquidity-provider-rewards
+func (p Private) GetLiquidityProviderRewards(epoch int) (*types.LiquidityProviderRewardsResponse, error) {
+	res, err := p.get("rewards/liquidity", epochParams(epoch))
+	if err != nil {
+		return nil, err
+	}
+	result := &types.LiquidityProviderRewardsResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
 	}
-	resp, err := p.execute(method, requestPath, headers, data)
+	return result, nil
+}
+
+// GetRetroactiveMiningRewards 查询追溯挖矿奖励
+// see https://docs.dydx.exchange/?json#get-retroactive-mining-rewards
+func (p Private) GetRetroactiveMiningRewards() (*types.RetroactiveMiningRewardsResponse, error) {
+	res, err := p.get("rewards/retroactive-mining", nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	result := &types.RetroactiveMiningRewardsResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode > 300 {
-		buf := new(bytes.Buffer)
-		buf.ReadFrom(resp.Body)
-		p.Logger.Printf("uri:%s, code: %d, err msg:%s", requestPath, resp.StatusCode, buf.String())
-		return nil, fmt.Errorf("uri:%v , status code: %d", requestPath, resp.StatusCode)
+// GetTransfers 查询充值、提现及转账记录
+// see https://docs.dydx.exchange/?json#get-transfers
+func (p Private) GetTransfers(input *types.TransferQueryParam) (*types.TransfersResponse, error) {
+	res, err := p.get("transfers", input.ToParams())
+	if err != nil {
+		return nil, err
+	}
+	result := &types.TransfersResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
 	}
-	responseBody, err := ioutil.ReadAll(resp.Body)
-	p.Logger.Printf("uri:%s,response body:%s", requestPath, responseBody)
-	return responseBody, err
+	return result, nil
 }
 
-func (p Private) execute(method string, requestPath string, headers map[string]string, data string) (*http.Response, error) {
-	requestPath = fmt.Sprintf("%s%s", p.Host, requestPath)
-	req, _ := http.NewRequest(method, requestPath, strings.NewReader(data))
+// GetOrderById 查询订单
+// see https://docs.dydx.exchange/?json#get-order-by-id
+func (p Private) GetOrderById(orderId string) (*types.OrderResponse, error) {
+	res, err := p.get("orders/"+orderId, nil)
+	if err != nil {
+		return nil, err
+	}
+	orderResponse := &types.OrderResponse{}
+	if err = json.Unmarshal(res, orderResponse); err != nil {
+		return nil, err
+	}
+	return orderResponse, nil
+}
 
-	for key, val := range headers {
-		req.Header.Add(key, val)
+// GetOrderByClientId 按客户端订单 id 查询订单
+// see https://docs.dydx.exchange/?json#get-order-by-client-id
+func (p Private) GetOrderByClientId(clientId string) (*types.OrderResponse, error) {
+	res, err := p.get("orders/client/"+clientId, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.OrderResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
 	}
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("User-Agent", "dydx/go")
+	return result, nil
+}
 
-	c := &http.Client{
-		Timeout: time.Second * 5,
+func epochParams(epoch int) url.Values {
+	params := url.Values{}
+	if epoch >= 0 {
+		params.Add("epoch", strconv.Itoa(epoch))
 	}
-	return c.Do(req)
+	return params
+}
 
+func (p Private) get(endpoint string, params url.Values) ([]byte, error) {
+	return p.request(http.MethodGet, common.GenerateQueryPath(endpoint, params), "")
 }
 
-func generateNowISO() string {
-	return time.Now().UTC().Format("2006-01-02T15:04:05.999Z")
+func (p Private) post(endpoint string, data interface{}) ([]byte, error) {
+	marshalData, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s request error: %w", endpoint, err)
+	}
+	return p.request(http.MethodPost, endpoint, string(marshalData))
+}
+
+func (p Private) put(endpoint string, data interface{}) ([]byte, error) {
+	marshalData, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s request error: %w", endpoint, err)
+	}
+	return p.request(http.MethodPut, endpoint, string(marshalData))
+}
+
+func (p Private) delete(endpoint string, params url.Values) ([]byte, error) {
+	return p.request(http.MethodDelete, common.GenerateQueryPath(endpoint, params), "")
+}
+
+// request 只有 GET/DELETE 按 RetryPolicy 重试, POST/PUT 不保证幂等, 不重试
+func (p Private) request(method, endpoint string, data string) ([]byte, error) {
+	attempts := 1
+	if method == http.MethodGet || method == http.MethodDelete {
+		attempts = p.RetryPolicy.attempts(method)
+	}
+	return p.requestWithRetry(method, endpoint, data, attempts, nil)
+}
+
+// requestWithRetry 每次尝试重新生成时间戳与签名, retried 不为 nil 时记录是否发生过重试
+func (p Private) requestWithRetry(method, endpoint, data string, attempts int, retried *bool) ([]byte, error) {
+	ctx := contextOrBackground(p.ctx)
+	for attempt := 1; ; attempt++ {
+		res, err := p.send(method, endpoint, data)
+		if err == nil || attempt >= attempts || ctx.Err() != nil || !isRetryable(err) {
+			return res, err
+		}
+		p.Logger.Printf("retry %s %s after error: %v", method, endpoint, err)
+		if sleepErr := sleepContext(ctx, p.RetryPolicy.backoff(attempt)); sleepErr != nil {
+			return nil, err
+		}
+		if retried != nil {
+			*retried = true
+		}
+	}
+}
+
+func (p Private) send(method, endpoint string, data string) ([]byte, error) {
+	isoTimestamp := common.GenerateNowISO()
+	requestPath := fmt.Sprintf("/v3/%s", endpoint)
+	headers := map[string]string{
+		"DYDX-SIGNATURE":  p.Sign(requestPath, method, isoTimestamp, data),
+		"DYDX-API-KEY":    p.ApiKeyCredentials.Key,
+		"DYDX-TIMESTAMP":  isoTimestamp,
+		"DYDX-PASSPHRASE": p.ApiKeyCredentials.Passphrase,
+	}
+	return doRequest(p.ctx, p.HttpClient, p.Host, p.Logger, method, requestPath, headers, data, true)
 }
 
 func (p Private) Sign(requestPath, method, isoTimestamp, body string) string {
-	message := fmt.Sprintf("%s%s%s%s", isoTimestamp, method, requestPath, body)
-	secret, _ := base64.URLEncoding.DecodeString(p.ApiKeyCredentials.Secret)
-	h := hmac.New(sha256.New, secret)
-	h.Write([]byte(message))
-	return base64.URLEncoding.EncodeToString(h.Sum(nil))
+	return p.ApiKeyCredentials.Sign(requestPath, method, isoTimestamp, body)
 }
@@ -0,0 +1,252 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/verichenn/dydx-v3-go/common"
+	"github.com/verichenn/dydx-v3-go/types"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type Public struct {
+	Host       string
+	HttpClient *http.Client
+	Logger     *log.Logger
+	ctx        context.Context
+}
+
+// WithContext 返回使用 ctx 发起请求的 Public, 可用于取消请求或设置单次调用的超时
+func (p Public) WithContext(ctx context.Context) *Public {
+	p.ctx = ctx
+	return &p
+}
+
+// GetMarkets 查询市场
+// see https://docs.dydx.exchange/?json#get-markets
+func (p Public) GetMarkets(market string) (*types.MarketsResponse, error) {
+	params := url.Values{}
+	if market != "" {
+		params.Add("market", market)
+	}
+	res, err := p.get("markets", params)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.MarketsResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetOrderBook 查询订单簿
+// see https://docs.dydx.exchange/?json#get-orderbook
+func (p Public) GetOrderBook(market string) (*types.OrderbookResponse, error) {
+	res, err := p.get("orderbook/"+market, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.OrderbookResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStats 查询市场统计, days 为 0 时使用服务端默认值
+// see https://docs.dydx.exchange/?json#get-market-stats
+func (p Public) GetStats(market string, days int) (*types.MarketStatsResponse, error) {
+	uri := "stats"
+	if market != "" {
+		uri = fmt.Sprintf("stats/%s", market)
+	}
+	params := url.Values{}
+	if days != 0 {
+		params.Add("days", strconv.Itoa(days))
+	}
+	res, err := p.get(uri, params)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.MarketStatsResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTrades 查询成交记录
+// see https://docs.dydx.exchange/?json#get-trades
+func (p Public) GetTrades(market, startingBeforeOrAt string) (*types.TradesResponse, error) {
+	params := url.Values{}
+	if startingBeforeOrAt != "" {
+		params.Add("startingBeforeOrAt", startingBeforeOrAt)
+	}
+	res, err := p.get("trades/"+market, params)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.TradesResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCandles 查询K线
+// see https://docs.dydx.exchange/?json#get-candles-for-market
+func (p Public) GetCandles(market string, input *types.CandleQueryParam) (*types.CandlesResponse, error) {
+	res, err := p.get("candles/"+market, input.ToParams())
+	if err != nil {
+		return nil, err
+	}
+	result := &types.CandlesResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetHistoricalFunding 查询历史资金费率
+// see https://docs.dydx.exchange/?json#get-historical-funding
+func (p Public) GetHistoricalFunding(market, effectiveBeforeOrAt string) (*types.HistoricalFundingResponse, error) {
+	params := url.Values{}
+	if effectiveBeforeOrAt != "" {
+		params.Add("effectiveBeforeOrAt", effectiveBeforeOrAt)
+	}
+	res, err := p.get("historical-funding/"+market, params)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.HistoricalFundingResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetConfig 查询全局配置
+// see https://docs.dydx.exchange/?json#get-global-configuration-variables
+func (p Public) GetConfig() (*types.ConfigResponse, error) {
+	res, err := p.get("config", nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.ConfigResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTime 查询服务器时间
+// see https://docs.dydx.exchange/?json#get-time
+func (p Public) GetTime() (*types.TimeResponse, error) {
+	res, err := p.get("time", nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.TimeResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CheckIfUserExists 查询以太坊地址是否已注册
+// see https://docs.dydx.exchange/?json#check-if-user-exists
+func (p Public) CheckIfUserExists(ethereumAddress string) (*types.ExistsResponse, error) {
+	params := url.Values{}
+	params.Add("ethereumAddress", ethereumAddress)
+	res, err := p.get("users/exists", params)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.ExistsResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CheckIfUsernameExists 查询用户名是否已被占用
+// see https://docs.dydx.exchange/?json#check-if-username-exists
+func (p Public) CheckIfUsernameExists(username string) (*types.ExistsResponse, error) {
+	params := url.Values{}
+	params.Add("username", username)
+	res, err := p.get("usernames", params)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.ExistsResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetInsuranceFundBalance 查询保险基金余额
+// see https://docs.dydx.exchange/?json#get-insurance-fund-balance
+func (p Public) GetInsuranceFundBalance() (*types.InsuranceFundBalanceResponse, error) {
+	res, err := p.get("insurance-fund/balance", nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.InsuranceFundBalanceResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetFastWithdrawal 查询快速提现的流动性提供者报价
+// see https://docs.dydx.exchange/?json#get-fast-withdrawal-liquidity
+func (p Public) GetFastWithdrawal(input *types.FastWithdrawalQueryParam) (*types.FastWithdrawalsResponse, error) {
+	res, err := p.get("fast-withdrawals", input.ToParams())
+	if err != nil {
+		return nil, err
+	}
+	result := &types.FastWithdrawalsResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPublicRetroactiveMiningRewards 查询任意以太坊地址的追溯挖矿奖励
+// see https://docs.dydx.exchange/?json#get-public-retroactive-mining-rewards
+func (p Public) GetPublicRetroactiveMiningRewards(ethereumAddress string) (*types.PublicRetroactiveMiningRewardsResponse, error) {
+	params := url.Values{}
+	params.Add("ethereumAddress", ethereumAddress)
+	res, err := p.get("rewards/public-retroactive-mining", params)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.PublicRetroactiveMiningRewardsResponse{}
+	if err = json.Unmarshal(res, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// VerifyEmail 验证邮箱
+// see https://docs.dydx.exchange/?json#verify-email
+func (p Public) VerifyEmail(token string) error {
+	params := url.Values{}
+	params.Add("token", token)
+	_, err := p.request(http.MethodPut, common.GenerateQueryPath("emails/verify-email", params))
+	return err
+}
+
+func (p Public) get(endpoint string, params url.Values) ([]byte, error) {
+	return p.request(http.MethodGet, common.GenerateQueryPath(endpoint, params))
+}
+
+func (p Public) request(method, endpoint string) ([]byte, error) {
+	requestPath := fmt.Sprintf("/v3/%s", endpoint)
+	return doRequest(p.ctx, p.HttpClient, p.Host, p.Logger, method, requestPath, nil, "", true)
+}
@@ -0,0 +1,90 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RetryPolicy Private 请求的重试策略, 只重试 429/5xx 及网络错误(含超时), 退避时间指数增长并加入随机抖动
+type RetryPolicy struct {
+	// MaxAttempts HTTP method -> 最大尝试次数(含首次), 未配置的 method 不重试.
+	// POST 只对 CreateOrder 生效, 其通过复用 ClientId 保证重试幂等
+	MaxAttempts map[string]int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultRetryPolicy GET 与 CreateOrder 最多尝试 3 次
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: map[string]int{
+			http.MethodGet:  3,
+			http.MethodPost: 3,
+		},
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: 2 * time.Second,
+	}
+}
+
+func (r *RetryPolicy) attempts(method string) int {
+	if r == nil || r.MaxAttempts[method] < 1 {
+		return 1
+	}
+	return r.MaxAttempts[method]
+}
+
+// backoff 第 attempt 次重试(从 1 开始)前的等待时间, 在 [d/2, d] 内随机
+func (r *RetryPolicy) backoff(attempt int) time.Duration {
+	d := r.MinBackoff
+	for i := 1; i < attempt && d < r.MaxBackoff; i++ {
+		d *= 2
+	}
+	if r.MaxBackoff > 0 && d > r.MaxBackoff {
+		d = r.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrServer)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// isDuplicateClientId 下单重试时, 之前的请求可能已成功, 服务端会以 clientId 重复拒绝
+func isDuplicateClientId(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrValidation) {
+		return false
+	}
+	for _, detail := range apiErr.Errors {
+		msg := strings.ToLower(detail.Msg)
+		if (detail.Param == "clientId" || strings.Contains(msg, "clientid")) && strings.Contains(msg, "exist") {
+			return true
+		}
+	}
+	return false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -6,6 +6,10 @@ type AccountResponse struct {
 	Account Account `json:"account"`
 }
 
+type AccountsResponse struct {
+	Accounts []Account `json:"accounts"`
+}
+
 type Account struct {
 	StarkKey           string              `json:"starkKey"`
 	PositionId         int64               `json:"positionId,string"`
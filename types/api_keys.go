@@ -0,0 +1,9 @@
+package types
+
+type ApiKeyResponse struct {
+	ApiKey ApiKey `json:"apiKey"`
+}
+
+type ApiKeysResponse struct {
+	ApiKeys []string `json:"apiKeys"`
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type FillsResponse struct {
+	Fills []Fill `json:"fills"`
+}
+
+type Fill struct {
+	ID        string    `json:"id"`
+	Side      string    `json:"side"`
+	Liquidity string    `json:"liquidity"`
+	Type      string    `json:"type"`
+	Market    string    `json:"market"`
+	OrderID   string    `json:"orderId"`
+	Price     string    `json:"price"`
+	Size      string    `json:"size"`
+	Fee       string    `json:"fee"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type FillQueryParam struct {
+	Market            string `json:"market"`
+	OrderId           string `json:"orderId"`
+	Limit             int    `json:"limit"`
+	CreatedBeforeOrAt string `json:"createdBeforeOrAt"`
+}
+
+func (o FillQueryParam) ToParams() url.Values {
+	params := url.Values{}
+	if o.Market != "" {
+		params.Add("market", o.Market)
+	}
+	if o.OrderId != "" {
+		params.Add("orderId", o.OrderId)
+	}
+	if o.Limit != 0 {
+		params.Add("limit", strconv.Itoa(o.Limit))
+	}
+	if o.CreatedBeforeOrAt != "" {
+		params.Add("createdBeforeOrAt", o.CreatedBeforeOrAt)
+	}
+	return params
+}
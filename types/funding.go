@@ -0,0 +1,68 @@
+package types
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type FundingPaymentsResponse struct {
+	FundingPayments []FundingPayment `json:"fundingPayments"`
+}
+
+type FundingPayment struct {
+	Market       string    `json:"market"`
+	Payment      string    `json:"payment"`
+	Rate         string    `json:"rate"`
+	PositionSize string    `json:"positionSize"`
+	Price        string    `json:"price"`
+	EffectiveAt  time.Time `json:"effectiveAt"`
+}
+
+type FundingPaymentQueryParam struct {
+	Market              string `json:"market"`
+	Limit               int    `json:"limit"`
+	EffectiveBeforeOrAt string `json:"effectiveBeforeOrAt"`
+}
+
+func (o FundingPaymentQueryParam) ToParams() url.Values {
+	params := url.Values{}
+	if o.Market != "" {
+		params.Add("market", o.Market)
+	}
+	if o.Limit != 0 {
+		params.Add("limit", strconv.Itoa(o.Limit))
+	}
+	if o.EffectiveBeforeOrAt != "" {
+		params.Add("effectiveBeforeOrAt", o.EffectiveBeforeOrAt)
+	}
+	return params
+}
+
+type HistoricalPnlResponse struct {
+	HistoricalPnl []HistoricalPnl `json:"historicalPnl"`
+}
+
+type HistoricalPnl struct {
+	AccountId    string    `json:"accountId"`
+	Equity       string    `json:"equity"`
+	TotalPnl     string    `json:"totalPnl"`
+	NetTransfers string    `json:"netTransfers"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type HistoricalPnlQueryParam struct {
+	EffectiveBeforeOrAt string `json:"effectiveBeforeOrAt"`
+	EffectiveAtOrAfter  string `json:"effectiveAtOrAfter"`
+}
+
+func (o HistoricalPnlQueryParam) ToParams() url.Values {
+	params := url.Values{}
+	if o.EffectiveBeforeOrAt != "" {
+		params.Add("effectiveBeforeOrAt", o.EffectiveBeforeOrAt)
+	}
+	if o.EffectiveAtOrAfter != "" {
+		params.Add("effectiveAtOrAfter", o.EffectiveAtOrAfter)
+	}
+	return params
+}
@@ -0,0 +1,133 @@
+package types
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type MarketsResponse struct {
+	Markets map[string]Market `json:"markets"`
+}
+
+type Market struct {
+	Market                           string    `json:"market"`
+	Status                           string    `json:"status"`
+	BaseAsset                        string    `json:"baseAsset"`
+	QuoteAsset                       string    `json:"quoteAsset"`
+	StepSize                         string    `json:"stepSize"`
+	TickSize                         string    `json:"tickSize"`
+	IndexPrice                       string    `json:"indexPrice"`
+	OraclePrice                      string    `json:"oraclePrice"`
+	PriceChange24H                   string    `json:"priceChange24H"`
+	NextFundingRate                  string    `json:"nextFundingRate"`
+	NextFundingAt                    time.Time `json:"nextFundingAt"`
+	MinOrderSize                     string    `json:"minOrderSize"`
+	Type                             string    `json:"type"`
+	InitialMarginFraction            string    `json:"initialMarginFraction"`
+	MaintenanceMarginFraction        string    `json:"maintenanceMarginFraction"`
+	TransferMarginFraction           string    `json:"transferMarginFraction"`
+	Volume24H                        string    `json:"volume24H"`
+	Trades24H                        string    `json:"trades24H"`
+	OpenInterest                     string    `json:"openInterest"`
+	IncrementalInitialMarginFraction string    `json:"incrementalInitialMarginFraction"`
+	IncrementalPositionSize          string    `json:"incrementalPositionSize"`
+	MaxPositionSize                  string    `json:"maxPositionSize"`
+	BaselinePositionSize             string    `json:"baselinePositionSize"`
+	AssetResolution                  string    `json:"assetResolution"`
+	SyntheticAssetId                 string    `json:"syntheticAssetId"`
+}
+
+type OrderbookResponse struct {
+	Offset string           `json:"offset,omitempty"`
+	Bids   []OrderbookOrder `json:"bids"`
+	Asks   []OrderbookOrder `json:"asks"`
+}
+
+type OrderbookOrder struct {
+	Price  string `json:"price"`
+	Size   string `json:"size"`
+	Offset string `json:"offset,omitempty"`
+}
+
+type MarketStatsResponse struct {
+	Markets map[string]MarketStats `json:"markets"`
+}
+
+type MarketStats struct {
+	Market      string `json:"market"`
+	Open        string `json:"open"`
+	High        string `json:"high"`
+	Low         string `json:"low"`
+	Close       string `json:"close"`
+	BaseVolume  string `json:"baseVolume"`
+	QuoteVolume string `json:"quoteVolume"`
+	Type        string `json:"type"`
+	Fees        string `json:"fees"`
+}
+
+type TradesResponse struct {
+	Trades []Trade `json:"trades"`
+}
+
+type Trade struct {
+	Side        string    `json:"side"`
+	Size        string    `json:"size"`
+	Price       string    `json:"price"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Liquidation bool      `json:"liquidation"`
+}
+
+type CandlesResponse struct {
+	Candles []Candle `json:"candles"`
+}
+
+type Candle struct {
+	StartedAt            time.Time `json:"startedAt"`
+	UpdatedAt            time.Time `json:"updatedAt"`
+	Market               string    `json:"market"`
+	Resolution           string    `json:"resolution"`
+	Low                  string    `json:"low"`
+	High                 string    `json:"high"`
+	Open                 string    `json:"open"`
+	Close                string    `json:"close"`
+	BaseTokenVolume      string    `json:"baseTokenVolume"`
+	Trades               string    `json:"trades"`
+	UsdVolume            string    `json:"usdVolume"`
+	StartingOpenInterest string    `json:"startingOpenInterest"`
+}
+
+type CandleQueryParam struct {
+	Resolution string `json:"resolution"`
+	FromISO    string `json:"fromISO"`
+	ToISO      string `json:"toISO"`
+	Limit      int    `json:"limit"`
+}
+
+func (o CandleQueryParam) ToParams() url.Values {
+	params := url.Values{}
+	if o.Resolution != "" {
+		params.Add("resolution", o.Resolution)
+	}
+	if o.FromISO != "" {
+		params.Add("fromISO", o.FromISO)
+	}
+	if o.ToISO != "" {
+		params.Add("toISO", o.ToISO)
+	}
+	if o.Limit != 0 {
+		params.Add("limit", strconv.Itoa(o.Limit))
+	}
+	return params
+}
+
+type HistoricalFundingResponse struct {
+	HistoricalFunding []HistoricalFunding `json:"historicalFunding"`
+}
+
+type HistoricalFunding struct {
+	Market      string    `json:"market"`
+	Rate        string    `json:"rate"`
+	Price       string    `json:"price"`
+	EffectiveAt time.Time `json:"effectiveAt"`
+}
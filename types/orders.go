@@ -1,6 +1,7 @@
 package types
 
 import (
+	"errors"
 	"net/url"
 	"strconv"
 	"time"
@@ -43,6 +44,53 @@ type OrderListResponse struct {
 	Orders []Order `json:"orders"`
 }
 
+// ActiveOrder active-orders 接口返回的精简订单
+type ActiveOrder struct {
+	ID            string `json:"id"`
+	AccountID     string `json:"accountId"`
+	Market        string `json:"market"`
+	Side          string `json:"side"`
+	Price         string `json:"price"`
+	RemainingSize string `json:"remainingSize"`
+}
+
+type ActiveOrdersResponse struct {
+	Orders []ActiveOrder `json:"orders"`
+}
+
+type CancelActiveOrdersResponse struct {
+	CancelOrders []ActiveOrder `json:"cancelOrders"`
+}
+
+// ActiveOrderQueryParam Market 必填, 指定 Id 时必须同时指定 Side
+type ActiveOrderQueryParam struct {
+	Market string `json:"market"`
+	Side   string `json:"side"`
+	Id     string `json:"id"`
+}
+
+func (o ActiveOrderQueryParam) Validate() error {
+	if o.Market == "" {
+		return errors.New("active orders: market is required")
+	}
+	if o.Id != "" && o.Side == "" {
+		return errors.New("active orders: side is required when id is set")
+	}
+	return nil
+}
+
+func (o ActiveOrderQueryParam) ToParams() url.Values {
+	params := url.Values{}
+	params.Add("market", o.Market)
+	if o.Side != "" {
+		params.Add("side", o.Side)
+	}
+	if o.Id != "" {
+		params.Add("id", o.Id)
+	}
+	return params
+}
+
 type OrderQueryParam struct {
 	Market             string `json:"market"`
 	Status             string `json:"status"`
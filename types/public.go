@@ -0,0 +1,43 @@
+package types
+
+type ConfigResponse struct {
+	CollateralAssetId             string                  `json:"collateralAssetId"`
+	CollateralTokenAddress        string                  `json:"collateralTokenAddress"`
+	DefaultMakerFee               string                  `json:"defaultMakerFee"`
+	DefaultTakerFee               string                  `json:"defaultTakerFee"`
+	ExchangeAddress               string                  `json:"exchangeAddress"`
+	MaxExpectedBatchLengthMinutes string                  `json:"maxExpectedBatchLengthMinutes"`
+	MaxFastWithdrawalAmount       string                  `json:"maxFastWithdrawalAmount"`
+	CancelOrderRateLimiting       CancelOrderRateLimiting `json:"cancelOrderRateLimiting"`
+	PlaceOrderRateLimiting        PlaceOrderRateLimiting  `json:"placeOrderRateLimiting"`
+}
+
+type CancelOrderRateLimiting struct {
+	MaxPointsMulti  int `json:"maxPointsMulti"`
+	MaxPointsSingle int `json:"maxPointsSingle"`
+	WindowSecMulti  int `json:"windowSecMulti"`
+	WindowSecSingle int `json:"windowSecSingle"`
+}
+
+type PlaceOrderRateLimiting struct {
+	MaxPoints                 int `json:"maxPoints"`
+	WindowSec                 int `json:"windowSec"`
+	TargetNotional            int `json:"targetNotional"`
+	MinLimitConsumption       int `json:"minLimitConsumption"`
+	MinMarketConsumption      int `json:"minMarketConsumption"`
+	MinTriggerableConsumption int `json:"minTriggerableConsumption"`
+	MaxOrderConsumption       int `json:"maxOrderConsumption"`
+}
+
+type TimeResponse struct {
+	Iso   string  `json:"iso"`
+	Epoch float64 `json:"epoch"`
+}
+
+type ExistsResponse struct {
+	Exists bool `json:"exists"`
+}
+
+type InsuranceFundBalanceResponse struct {
+	Balance float64 `json:"balance"`
+}
@@ -0,0 +1,80 @@
+package types
+
+import "time"
+
+// TradingRewardsResponse 交易奖励, 各项均为当前账户值与全体总值
+type TradingRewardsResponse struct {
+	Epoch            int                 `json:"epoch"`
+	EpochStart       time.Time           `json:"epochStart"`
+	EpochEnd         time.Time           `json:"epochEnd"`
+	Fees             RewardsFees         `json:"fees"`
+	OpenInterest     RewardsOpenInterest `json:"openInterest"`
+	Weight           RewardsWeight       `json:"weight"`
+	StakedDYDX       RewardsStakedDYDX   `json:"stakedDYDX"`
+	TotalRewards     string              `json:"totalRewards"`
+	EstimatedRewards string              `json:"estimatedRewards"`
+}
+
+type RewardsFees struct {
+	FeesPaid      string `json:"feesPaid"`
+	TotalFeesPaid string `json:"totalFeesPaid"`
+}
+
+type RewardsOpenInterest struct {
+	AverageOpenInterest      string `json:"averageOpenInterest"`
+	TotalAverageOpenInterest string `json:"totalAverageOpenInterest"`
+}
+
+type RewardsWeight struct {
+	Weight      string `json:"weight"`
+	TotalWeight string `json:"totalWeight"`
+}
+
+type RewardsStakedDYDX struct {
+	AverageStakedDYDX         string `json:"averageStakedDYDX"`
+	AverageStakedDYDXWithFees string `json:"averageStakedDYDXWithFees"`
+	TotalAverageStakedDYDX    string `json:"totalAverageStakedDYDX"`
+}
+
+// LiquidityProviderRewardsResponse 流动性提供者奖励, Markets 以市场名为 key
+type LiquidityProviderRewardsResponse struct {
+	Epoch      int                               `json:"epoch"`
+	EpochStart time.Time                         `json:"epochStart"`
+	EpochEnd   time.Time                         `json:"epochEnd"`
+	Markets    map[string]LiquidityMarketRewards `json:"markets"`
+	StakedDYDX RewardsStakedDYDX                 `json:"stakedDYDX"`
+}
+
+type LiquidityMarketRewards struct {
+	Market           string `json:"market"`
+	DepthSpreadScore string `json:"depthSpreadScore"`
+	Uptime           string `json:"uptime"`
+	LinkedUptime     string `json:"linkedUptime"`
+	MaxUptime        string `json:"maxUptime"`
+	Score            string `json:"score"`
+	TotalScore       string `json:"totalScore"`
+	MakerVolume      string `json:"makerVolume"`
+	TotalMakerVolume string `json:"totalMakerVolume"`
+	TotalRewards     string `json:"totalRewards"`
+	EstimatedRewards string `json:"estimatedRewards"`
+	SecondaryRewards string `json:"secondaryRewards"`
+}
+
+type RetroactiveMiningRewardsResponse struct {
+	Epoch             int                      `json:"epoch"`
+	EpochStart        time.Time                `json:"epochStart"`
+	EpochEnd          time.Time                `json:"epochEnd"`
+	RetroactiveMining RetroactiveMiningRewards `json:"retroactiveMining"`
+	EstimatedRewards  string                   `json:"estimatedRewards"`
+}
+
+type RetroactiveMiningRewards struct {
+	Allocation   string `json:"allocation"`
+	TargetVolume string `json:"targetVolume"`
+	Volume       string `json:"volume"`
+}
+
+type PublicRetroactiveMiningRewardsResponse struct {
+	Allocation   string `json:"allocation"`
+	TargetVolume string `json:"targetVolume"`
+}
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type TransfersResponse struct {
+	Transfers []Transfer `json:"transfers"`
+}
+
+type Transfer struct {
+	ID              string     `json:"id"`
+	Type            string     `json:"type"`
+	DebitAsset      string     `json:"debitAsset"`
+	CreditAsset     string     `json:"creditAsset"`
+	DebitAmount     string     `json:"debitAmount"`
+	CreditAmount    string     `json:"creditAmount"`
+	TransactionHash string     `json:"transactionHash"`
+	Status          string     `json:"status"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	ConfirmedAt     *time.Time `json:"confirmedAt"`
+	ClientId        string     `json:"clientId"`
+	FromAddress     string     `json:"fromAddress"`
+	ToAddress       string     `json:"toAddress"`
+}
+
+type WithdrawalResponse struct {
+	Withdrawal Transfer `json:"withdrawal"`
+}
+
+type TransferResponse struct {
+	Transfer Transfer `json:"transfer"`
+}
+
+type TransferQueryParam struct {
+	Type              string `json:"type"`
+	Limit             int    `json:"limit"`
+	CreatedBeforeOrAt string `json:"createdBeforeOrAt"`
+}
+
+func (o TransferQueryParam) ToParams() url.Values {
+	params := url.Values{}
+	if o.Type != "" {
+		params.Add("transferType", o.Type)
+	}
+	if o.Limit != 0 {
+		params.Add("limit", strconv.Itoa(o.Limit))
+	}
+	if o.CreatedBeforeOrAt != "" {
+		params.Add("createdBeforeOrAt", o.CreatedBeforeOrAt)
+	}
+	return params
+}
+
+type FastWithdrawalQueryParam struct {
+	CreditAsset  string `json:"creditAsset"`
+	CreditAmount string `json:"creditAmount"`
+	DebitAmount  string `json:"debitAmount"`
+}
+
+func (o FastWithdrawalQueryParam) ToParams() url.Values {
+	params := url.Values{}
+	if o.CreditAsset != "" {
+		params.Add("creditAsset", o.CreditAsset)
+	}
+	if o.CreditAmount != "" {
+		params.Add("creditAmount", o.CreditAmount)
+	}
+	if o.DebitAmount != "" {
+		params.Add("debitAmount", o.DebitAmount)
+	}
+	return params
+}
+
+type FastWithdrawalsResponse struct {
+	LiquidityProviders map[string]LiquidityProvider `json:"liquidityProviders"`
+}
+
+type LiquidityProvider struct {
+	AvailableFunds string               `json:"availableFunds"`
+	StarkKey       string               `json:"starkKey"`
+	Quote          *FastWithdrawalQuote `json:"quote"`
+}
+
+type FastWithdrawalQuote struct {
+	CreditAsset  string `json:"creditAsset"`
+	CreditAmount string `json:"creditAmount"`
+	DebitAmount  string `json:"debitAmount"`
+}
+
+// BestQuote 选择到账金额(creditAmount)最大且手续费(debitAmount - creditAmount)不超过 maxFee 的流动性提供者,
+// 返回其 positionId; 可用资金不足的报价会被忽略
+func (r FastWithdrawalsResponse) BestQuote(maxFee string) (string, *LiquidityProvider, error) {
+	limit, err := decimal.NewFromString(maxFee)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid max fee: %s", maxFee)
+	}
+	var bestId string
+	var best *LiquidityProvider
+	var bestCredit decimal.Decimal
+	for positionId, provider := range r.LiquidityProviders {
+		if provider.Quote == nil {
+			continue
+		}
+		credit, err := decimal.NewFromString(provider.Quote.CreditAmount)
+		if err != nil {
+			continue
+		}
+		debit, err := decimal.NewFromString(provider.Quote.DebitAmount)
+		if err != nil {
+			continue
+		}
+		funds, err := decimal.NewFromString(provider.AvailableFunds)
+		if err != nil || funds.LessThan(credit) || debit.Sub(credit).GreaterThan(limit) {
+			continue
+		}
+		if best == nil || credit.GreaterThan(bestCredit) || (credit.Equal(bestCredit) && positionId < bestId) {
+			provider := provider
+			bestId, best, bestCredit = positionId, &provider, credit
+		}
+	}
+	if best == nil {
+		return "", nil, errors.New("no liquidity provider quote within max fee")
+	}
+	return bestId, best, nil
+}
@@ -0,0 +1,74 @@
+package types
+
+type ApiKey struct {
+	Key        string `json:"key"`
+	Secret     string `json:"secret"`
+	Passphrase string `json:"passphrase"`
+}
+
+type User struct {
+	EthereumAddress         string                 `json:"ethereumAddress"`
+	IsRegistered            bool                   `json:"isRegistered"`
+	Email                   string                 `json:"email"`
+	Username                string                 `json:"username"`
+	ReferredByAffiliateLink string                 `json:"referredByAffiliateLink"`
+	MakerFeeRate            string                 `json:"makerFeeRate"`
+	TakerFeeRate            string                 `json:"takerFeeRate"`
+	MakerVolume30D          string                 `json:"makerVolume30D"`
+	TakerVolume30D          string                 `json:"takerVolume30D"`
+	Fees30D                 string                 `json:"fees30D"`
+	UserData                map[string]interface{} `json:"userData"`
+	DydxTokenBalance        string                 `json:"dydxTokenBalance"`
+	StakedDydxTokenBalance  string                 `json:"stakedDydxTokenBalance"`
+	IsEmailVerified         bool                   `json:"isEmailVerified"`
+	Country                 string                 `json:"country"`
+	IsSharingUsername       bool                   `json:"isSharingUsername"`
+	IsSharingAddress        bool                   `json:"isSharingAddress"`
+}
+
+type UserResponse struct {
+	User User `json:"user"`
+}
+
+type CreateUserResponse struct {
+	ApiKey  ApiKey  `json:"apiKey"`
+	User    User    `json:"user"`
+	Account Account `json:"account"`
+}
+
+type PrivateProfileResponse struct {
+	Username                   string                `json:"username"`
+	PublicId                   string                `json:"publicId"`
+	EthereumAddress            string                `json:"ethereumAddress"`
+	DYDXHoldings               string                `json:"DYDXHoldings"`
+	StakedDYDXHoldings         string                `json:"stakedDYDXHoldings"`
+	HedgiesHeld                []int                 `json:"hedgiesHeld"`
+	TwitterHandle              string                `json:"twitterHandle"`
+	AffiliateLinks             []AffiliateLink       `json:"affiliateLinks"`
+	AffiliateApplicationStatus string                `json:"affiliateApplicationStatus"`
+	TradingLeagues             ProfileTradingLeagues `json:"tradingLeagues"`
+	TradingPnls                ProfileTradingPnls    `json:"tradingPnls"`
+	TradingRewards             ProfileTradingRewards `json:"tradingRewards"`
+}
+
+type AffiliateLink struct {
+	Link         string `json:"link"`
+	DiscountRate string `json:"discountRate"`
+}
+
+type ProfileTradingLeagues struct {
+	CurrentLeague        string `json:"currentLeague"`
+	CurrentLeagueRanking int    `json:"currentLeagueRanking"`
+}
+
+type ProfileTradingPnls struct {
+	AbsolutePnl30D string `json:"absolutePnl30D"`
+	PercentPnl30D  string `json:"percentPnl30D"`
+	Volume30D      string `json:"volume30D"`
+}
+
+type ProfileTradingRewards struct {
+	CurEpoch                  int    `json:"curEpoch"`
+	CurEpochEstimatedRewards  string `json:"curEpochEstimatedRewards"`
+	PrevEpochEstimatedRewards string `json:"prevEpochEstimatedRewards"`
+}
@@ -0,0 +1,106 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WsMessage websocket 推送消息的通用外层结构
+type WsMessage struct {
+	Type         string          `json:"type"`
+	ConnectionId string          `json:"connection_id"`
+	MessageId    int64           `json:"message_id"`
+	Channel      string          `json:"channel"`
+	Id           string          `json:"id"`
+	Contents     json.RawMessage `json:"contents"`
+	Message      string          `json:"message"`
+}
+
+// WsOrderbookMessage v3_orderbook 频道消息, Snapshot 为 true 时是订阅成功后的全量快照
+type WsOrderbookMessage struct {
+	Snapshot  bool
+	Market    string
+	MessageId int64
+	Offset    string
+	Bids      []OrderbookOrder
+	Asks      []OrderbookOrder
+}
+
+type wsOrderbookContents struct {
+	Offset string            `json:"offset"`
+	Bids   []json.RawMessage `json:"bids"`
+	Asks   []json.RawMessage `json:"asks"`
+}
+
+// ParseWsOrderbookMessage 快照中的档位为对象, 增量中的档位为 [price, size] 数组, 两种格式统一解析为 OrderbookOrder
+func ParseWsOrderbookMessage(msg *WsMessage) (*WsOrderbookMessage, error) {
+	contents := &wsOrderbookContents{}
+	if err := json.Unmarshal(msg.Contents, contents); err != nil {
+		return nil, err
+	}
+	bids, err := parseWsOrderbookLevels(contents.Bids, contents.Offset)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parseWsOrderbookLevels(contents.Asks, contents.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &WsOrderbookMessage{
+		Snapshot:  msg.Type == "subscribed",
+		Market:    msg.Id,
+		MessageId: msg.MessageId,
+		Offset:    contents.Offset,
+		Bids:      bids,
+		Asks:      asks,
+	}, nil
+}
+
+func parseWsOrderbookLevels(raw []json.RawMessage, offset string) ([]OrderbookOrder, error) {
+	levels := make([]OrderbookOrder, 0, len(raw))
+	for _, item := range raw {
+		level := OrderbookOrder{}
+		if len(item) > 0 && item[0] == '[' {
+			var pair []string
+			if err := json.Unmarshal(item, &pair); err != nil {
+				return nil, err
+			}
+			if len(pair) != 2 {
+				return nil, fmt.Errorf("invalid orderbook level: %s", item)
+			}
+			level.Price, level.Size, level.Offset = pair[0], pair[1], offset
+		} else if err := json.Unmarshal(item, &level); err != nil {
+			return nil, err
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// WsTradesMessage v3_trades 频道消息
+type WsTradesMessage struct {
+	Snapshot  bool
+	Market    string
+	MessageId int64
+	Trades    []Trade `json:"trades"`
+}
+
+// WsMarketsMessage v3_markets 频道消息, 增量消息中只包含发生变化的字段
+type WsMarketsMessage struct {
+	Snapshot  bool
+	MessageId int64
+	Markets   map[string]Market
+}
+
+// WsAccountMessage v3_accounts 频道消息, 快照包含 Account, 增量包含 Accounts
+type WsAccountMessage struct {
+	Snapshot        bool
+	MessageId       int64
+	Account         *Account         `json:"account"`
+	Accounts        []Account        `json:"accounts"`
+	Orders          []Order          `json:"orders"`
+	Positions       []Position       `json:"positions"`
+	Fills           []Fill           `json:"fills"`
+	Transfers       []Transfer       `json:"transfers"`
+	FundingPayments []FundingPayment `json:"fundingPayments"`
+}
@@ -0,0 +1,530 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/verichenn/dydx-v3-go/common"
+	"github.com/verichenn/dydx-v3-go/modules"
+	"github.com/verichenn/dydx-v3-go/types"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Channels
+const (
+	ChannelAccounts  = "v3_accounts"
+	ChannelOrderbook = "v3_orderbook"
+	ChannelTrades    = "v3_trades"
+	ChannelMarkets   = "v3_markets"
+)
+
+// Event Types
+const (
+	EventDisconnected = "DISCONNECTED"
+	EventReconnected  = "RECONNECTED"
+	EventMessageGap   = "MESSAGE_GAP"
+)
+
+const (
+	accountsRequestPath        = "/ws/accounts"
+	defaultMinReconnectBackoff = time.Second
+	defaultMaxReconnectBackoff = 30 * time.Second
+)
+
+var (
+	ErrNotConnected = errors.New("websocket not connected")
+	// ErrMessageGap 同一连接上的 message id 不连续, 中间有消息丢失
+	ErrMessageGap = errors.New("websocket message id gap")
+)
+
+type Client struct {
+	Host              string
+	ApiKeyCredentials *modules.ApiKeyCredentials
+	// DefaultAddress 用于计算账户 id, 账户频道的消息按账户 id 分发到对应 account number 的订阅
+	DefaultAddress string
+	Logger         *log.Logger
+
+	// 断线后按指数退避自动重连, 为 0 时使用默认值; DisableReconnect 为 true 时断线直接关闭所有订阅
+	MinReconnectBackoff time.Duration
+	MaxReconnectBackoff time.Duration
+	DisableReconnect    bool
+
+	conn          *websocket.Conn
+	writeMu       sync.Mutex
+	mu            sync.Mutex
+	subscriptions map[string]*subscription
+	events        chan Event
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+// Event 连接状态事件, 收到 EventReconnected 后本地维护的订单、订单簿等状态需要通过 REST 重新核对;
+// 收到 EventMessageGap 时客户端已重新订阅全部频道, 各频道会重新推送快照
+type Event struct {
+	Type string
+	Err  error
+}
+
+type subscription struct {
+	channel string
+	id      string
+	request func() map[string]interface{}
+	deliver func(msg *types.WsMessage, done <-chan struct{}) error
+	close   func()
+
+	mu     sync.Mutex
+	once   sync.Once
+	done   chan struct{}
+	closed bool
+}
+
+func (s *subscription) handle(msg *types.WsMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	return s.deliver(msg, s.done)
+}
+
+// stop 先关闭 done 以唤醒阻塞中的投递, 再关闭消费者 channel
+func (s *subscription) stop() {
+	s.once.Do(func() {
+		close(s.done)
+		s.mu.Lock()
+		s.closed = true
+		s.close()
+		s.mu.Unlock()
+	})
+}
+
+// Connect 建立 websocket 连接并开始读取消息
+func (c *Client) Connect() error {
+	conn, _, err := websocket.DefaultDialer.Dial(c.Host, http.Header{})
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.conn = conn
+	if c.subscriptions == nil {
+		c.subscriptions = map[string]*subscription{}
+	}
+	if c.events == nil {
+		c.events = make(chan Event, 16)
+	}
+	c.done = make(chan struct{})
+	c.closeOnce = sync.Once{}
+	c.mu.Unlock()
+
+	go c.readLoop(conn)
+	return nil
+}
+
+// Close 关闭连接并停止重连, 所有订阅的 channel 都会被关闭
+func (c *Client) Close() error {
+	c.mu.Lock()
+	conn, done := c.conn, c.done
+	c.mu.Unlock()
+	if conn == nil {
+		return ErrNotConnected
+	}
+	c.closeOnce.Do(func() { close(done) })
+	return conn.Close()
+}
+
+// Events 返回连接状态事件, 消费不及时的事件会被丢弃
+func (c *Client) Events() <-chan Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.events == nil {
+		c.events = make(chan Event, 16)
+	}
+	return c.events
+}
+
+// SubscribeOrderbook 订阅订单簿, 第一条消息为全量快照, 之后为按 offset 排序的增量
+func (c *Client) SubscribeOrderbook(market string) (<-chan *types.WsOrderbookMessage, error) {
+	ch := make(chan *types.WsOrderbookMessage, 64)
+	request := func() map[string]interface{} {
+		return map[string]interface{}{
+			"type":           "subscribe",
+			"channel":        ChannelOrderbook,
+			"id":             market,
+			"includeOffsets": true,
+		}
+	}
+	deliver := func(msg *types.WsMessage, done <-chan struct{}) error {
+		data, err := types.ParseWsOrderbookMessage(msg)
+		if err != nil {
+			return err
+		}
+		select {
+		case ch <- data:
+		case <-done:
+		}
+		return nil
+	}
+	if err := c.subscribe(ChannelOrderbook, market, request, deliver, func() { close(ch) }); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// SubscribeTrades 订阅成交
+func (c *Client) SubscribeTrades(market string) (<-chan *types.WsTradesMessage, error) {
+	ch := make(chan *types.WsTradesMessage, 64)
+	request := func() map[string]interface{} {
+		return map[string]interface{}{
+			"type":    "subscribe",
+			"channel": ChannelTrades,
+			"id":      market,
+		}
+	}
+	deliver := func(msg *types.WsMessage, done <-chan struct{}) error {
+		data := &types.WsTradesMessage{}
+		if err := json.Unmarshal(msg.Contents, data); err != nil {
+			return err
+		}
+		data.Snapshot = msg.Type == "subscribed"
+		data.Market = msg.Id
+		data.MessageId = msg.MessageId
+		select {
+		case ch <- data:
+		case <-done:
+		}
+		return nil
+	}
+	if err := c.subscribe(ChannelTrades, market, request, deliver, func() { close(ch) }); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// SubscribeMarkets 订阅全部市场行情
+func (c *Client) SubscribeMarkets() (<-chan *types.WsMarketsMessage, error) {
+	ch := make(chan *types.WsMarketsMessage, 64)
+	request := func() map[string]interface{} {
+		return map[string]interface{}{
+			"type":    "subscribe",
+			"channel": ChannelMarkets,
+		}
+	}
+	deliver := func(msg *types.WsMessage, done <-chan struct{}) error {
+		data := &types.WsMarketsMessage{
+			Snapshot:  msg.Type == "subscribed",
+			MessageId: msg.MessageId,
+		}
+		if data.Snapshot {
+			snapshot := &types.MarketsResponse{}
+			if err := json.Unmarshal(msg.Contents, snapshot); err != nil {
+				return err
+			}
+			data.Markets = snapshot.Markets
+		} else if err := json.Unmarshal(msg.Contents, &data.Markets); err != nil {
+			return err
+		}
+		select {
+		case ch <- data:
+		case <-done:
+		}
+		return nil
+	}
+	if err := c.subscribe(ChannelMarkets, "", request, deliver, func() { close(ch) }); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// SubscribeAccount 订阅私有账户频道, 使用 API Key 签名鉴权; 同一连接可同时订阅多个 account number
+func (c *Client) SubscribeAccount(accountNumber int) (<-chan *types.WsAccountMessage, error) {
+	if c.ApiKeyCredentials == nil {
+		return nil, errors.New("api key credentials required for " + ChannelAccounts)
+	}
+	if c.DefaultAddress == "" {
+		return nil, errors.New("default address required for " + ChannelAccounts)
+	}
+	ch := make(chan *types.WsAccountMessage, 64)
+	// 每次(重新)订阅都使用新的时间戳签名
+	request := func() map[string]interface{} {
+		isoTimestamp := common.GenerateNowISO()
+		return map[string]interface{}{
+			"type":          "subscribe",
+			"channel":       ChannelAccounts,
+			"accountNumber": strconv.Itoa(accountNumber),
+			"apiKey":        c.ApiKeyCredentials.Key,
+			"passphrase":    c.ApiKeyCredentials.Passphrase,
+			"timestamp":     isoTimestamp,
+			"signature":     c.ApiKeyCredentials.Sign(accountsRequestPath, http.MethodGet, isoTimestamp, ""),
+		}
+	}
+	deliver := func(msg *types.WsMessage, done <-chan struct{}) error {
+		data := &types.WsAccountMessage{}
+		if err := json.Unmarshal(msg.Contents, data); err != nil {
+			return err
+		}
+		data.Snapshot = msg.Type == "subscribed"
+		data.MessageId = msg.MessageId
+		select {
+		case ch <- data:
+		case <-done:
+		}
+		return nil
+	}
+	accountId := common.GetAccountIdByNumber(c.DefaultAddress, accountNumber)
+	if err := c.subscribe(ChannelAccounts, accountId, request, deliver, func() { close(ch) }); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// UnsubscribeAccount 取消指定 account number 的账户订阅
+func (c *Client) UnsubscribeAccount(accountNumber int) error {
+	return c.Unsubscribe(ChannelAccounts, common.GetAccountIdByNumber(c.DefaultAddress, accountNumber))
+}
+
+// Unsubscribe 取消订阅并关闭对应的 channel
+func (c *Client) Unsubscribe(channel, id string) error {
+	c.mu.Lock()
+	sub, ok := c.subscriptions[subscriptionKey(channel, id)]
+	delete(c.subscriptions, subscriptionKey(channel, id))
+	c.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("not subscribed to %s %s", channel, id)
+	}
+	request := map[string]interface{}{
+		"type":    "unsubscribe",
+		"channel": channel,
+	}
+	if id != "" {
+		request["id"] = id
+	}
+	err := c.send(request)
+	sub.stop()
+	return err
+}
+
+// Resubscribe 对已有订阅先退订再重新订阅, 不关闭对应的 channel; 订单簿频道会重新推送带 offset 的全量快照
+func (c *Client) Resubscribe(channel, id string) error {
+	c.mu.Lock()
+	sub, ok := c.subscriptions[subscriptionKey(channel, id)]
+	c.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("not subscribed to %s %s", channel, id)
+	}
+	request := map[string]interface{}{
+		"type":    "unsubscribe",
+		"channel": channel,
+	}
+	if id != "" {
+		request["id"] = id
+	}
+	if err := c.send(request); err != nil {
+		return err
+	}
+	return c.send(sub.request())
+}
+
+func (c *Client) subscribe(channel, id string, request func() map[string]interface{}, deliver func(msg *types.WsMessage, done <-chan struct{}) error, closeFunc func()) error {
+	key := subscriptionKey(channel, id)
+	c.mu.Lock()
+	if c.conn == nil {
+		c.mu.Unlock()
+		return ErrNotConnected
+	}
+	if _, ok := c.subscriptions[key]; ok {
+		c.mu.Unlock()
+		return fmt.Errorf("already subscribed to %s %s", channel, id)
+	}
+	c.subscriptions[key] = &subscription{
+		channel: channel,
+		id:      id,
+		request: request,
+		deliver: deliver,
+		close:   closeFunc,
+		done:    make(chan struct{}),
+	}
+	c.mu.Unlock()
+
+	if err := c.send(request()); err != nil {
+		c.mu.Lock()
+		delete(c.subscriptions, key)
+		c.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (c *Client) send(request map[string]interface{}) error {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn == nil {
+		return ErrNotConnected
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return conn.WriteJSON(request)
+}
+
+func (c *Client) readLoop(conn *websocket.Conn) {
+	defer c.closeSubscriptions()
+	// message_id 按连接计数, 所有频道共用同一序列, 重连后重新计数
+	var lastMessageId int64
+	hasMessageId := false
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			c.Logger.Printf("websocket read error: %v", err)
+			if c.DisableReconnect || c.isClosed() {
+				return
+			}
+			c.emit(Event{Type: EventDisconnected, Err: err})
+			if conn = c.reconnect(); conn == nil {
+				return
+			}
+			hasMessageId = false
+			c.emit(Event{Type: EventReconnected})
+			continue
+		}
+		msg := &types.WsMessage{}
+		if err = json.Unmarshal(data, msg); err != nil {
+			c.Logger.Printf("websocket invalid message: %s", data)
+			continue
+		}
+		if hasMessageId && msg.MessageId != lastMessageId+1 {
+			c.recoverGap(lastMessageId+1, msg.MessageId)
+		}
+		lastMessageId, hasMessageId = msg.MessageId, true
+		c.dispatch(msg)
+	}
+}
+
+// recoverGap 连接上丢失了消息时无法判断属于哪个频道, 因此重新订阅全部频道以获取新的快照
+func (c *Client) recoverGap(expected, got int64) {
+	err := fmt.Errorf("%w: expected %d, got %d", ErrMessageGap, expected, got)
+	c.Logger.Printf("websocket %v, resubscribe", err)
+	c.emit(Event{Type: EventMessageGap, Err: err})
+	c.mu.Lock()
+	subscriptions := make([]*subscription, 0, len(c.subscriptions))
+	for _, sub := range c.subscriptions {
+		subscriptions = append(subscriptions, sub)
+	}
+	c.mu.Unlock()
+	for _, sub := range subscriptions {
+		if err := c.Resubscribe(sub.channel, sub.id); err != nil {
+			c.Logger.Printf("websocket resubscribe error: %v", err)
+		}
+	}
+}
+
+func (c *Client) dispatch(msg *types.WsMessage) {
+	switch msg.Type {
+	case "subscribed", "channel_data":
+		sub := c.lookup(msg.Channel, msg.Id)
+		if sub == nil {
+			return
+		}
+		if err := sub.handle(msg); err != nil {
+			c.Logger.Printf("websocket channel:%s, id:%s, parse error: %v", msg.Channel, msg.Id, err)
+		}
+	case "error":
+		c.Logger.Printf("websocket error message: %s", msg.Message)
+	}
+}
+
+// reconnect 按指数退避重连, 成功后恢复之前的全部订阅; 调用 Close 后返回 nil
+func (c *Client) reconnect() *websocket.Conn {
+	backoff, maxBackoff := c.MinReconnectBackoff, c.MaxReconnectBackoff
+	if backoff <= 0 {
+		backoff = defaultMinReconnectBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxReconnectBackoff
+	}
+	for {
+		select {
+		case <-c.done:
+			return nil
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+
+		conn, _, err := websocket.DefaultDialer.Dial(c.Host, http.Header{})
+		if err != nil {
+			c.Logger.Printf("websocket reconnect error: %v", err)
+			continue
+		}
+		c.mu.Lock()
+		c.conn = conn
+		subscriptions := make([]*subscription, 0, len(c.subscriptions))
+		for _, sub := range c.subscriptions {
+			subscriptions = append(subscriptions, sub)
+		}
+		c.mu.Unlock()
+
+		if err = c.resubscribe(subscriptions); err != nil {
+			c.Logger.Printf("websocket resubscribe error: %v", err)
+			conn.Close()
+			continue
+		}
+		if c.isClosed() {
+			conn.Close()
+			return nil
+		}
+		return conn
+	}
+}
+
+func (c *Client) resubscribe(subscriptions []*subscription) error {
+	for _, sub := range subscriptions {
+		if err := c.send(sub.request()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) isClosed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Client) emit(event Event) {
+	select {
+	case c.events <- event:
+	default:
+	}
+}
+
+// lookup 账户频道的订阅以账户 id 为 key, 与推送消息的 id 一致
+func (c *Client) lookup(channel, id string) *subscription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.subscriptions[subscriptionKey(channel, id)]
+}
+
+func (c *Client) closeSubscriptions() {
+	c.mu.Lock()
+	subscriptions := c.subscriptions
+	c.subscriptions = map[string]*subscription{}
+	c.conn = nil
+	c.mu.Unlock()
+	for _, sub := range subscriptions {
+		sub.stop()
+	}
+}
+
+func subscriptionKey(channel, id string) string {
+	return channel + ":" + id
+}
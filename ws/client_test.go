@@ -0,0 +1,213 @@
+package ws
+
+import (
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/verichenn/dydx-v3-go/common"
+	"github.com/verichenn/dydx-v3-go/modules"
+	"github.com/verichenn/dydx-v3-go/types"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestServer 本地 websocket 服务, 每收到一条订阅请求就交给 handler 处理
+func newTestServer(t *testing.T, handler func(conn *websocket.Conn, request map[string]interface{})) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"connected","connection_id":"c1","message_id":0}`))
+		for {
+			request := map[string]interface{}{}
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+			handler(conn, request)
+		}
+	}))
+}
+
+const testAddress = "0x90F8bf6A479f320ead074411a4B0e7944Ea8c9C1"
+
+func newTestClient(server *httptest.Server) *Client {
+	return &Client{
+		Host:           "ws" + strings.TrimPrefix(server.URL, "http"),
+		DefaultAddress: testAddress,
+		Logger:         log.New(os.Stderr, "dydx-v3-go ", log.LstdFlags),
+		ApiKeyCredentials: &modules.ApiKeyCredentials{
+			Key:        "key",
+			Secret:     "c2VjcmV0",
+			Passphrase: "passphrase",
+		},
+	}
+}
+
+func TestSubscribeOrderbook(t *testing.T) {
+	server := newTestServer(t, func(conn *websocket.Conn, request map[string]interface{}) {
+		if request["channel"] != ChannelOrderbook || request["id"] != "BTC-USD" {
+			t.Errorf("unexpected request %v", request)
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"subscribed","channel":"v3_orderbook","id":"BTC-USD","message_id":1,
+			"contents":{"bids":[{"price":"100","size":"1","offset":"10"}],"asks":[{"price":"101","size":"2","offset":"11"}]}}`))
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"channel_data","channel":"v3_orderbook","id":"BTC-USD","message_id":2,
+			"contents":{"offset":"12","bids":[["100","0"]],"asks":[]}}`))
+	})
+	defer server.Close()
+
+	client := newTestClient(server)
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ch, err := client.SubscribeOrderbook("BTC-USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := <-ch
+	if !snapshot.Snapshot || snapshot.Bids[0].Offset != "10" || snapshot.Asks[0].Size != "2" {
+		t.Errorf("unexpected snapshot %+v", snapshot)
+	}
+	update := <-ch
+	if update.Snapshot || update.Offset != "12" || update.Bids[0].Size != "0" || update.Bids[0].Offset != "12" {
+		t.Errorf("unexpected update %+v", update)
+	}
+}
+
+func TestSubscribeAccount(t *testing.T) {
+	var client *Client
+	server := newTestServer(t, func(conn *websocket.Conn, request map[string]interface{}) {
+		timestamp, _ := request["timestamp"].(string)
+		expected := client.ApiKeyCredentials.Sign(accountsRequestPath, http.MethodGet, timestamp, "")
+		if request["channel"] != ChannelAccounts || request["signature"] != expected || request["accountNumber"] != "0" {
+			t.Errorf("unexpected request %v", request)
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"type":"subscribed","channel":"v3_accounts","id":"%s","message_id":1,
+			"contents":{"account":{"positionId":"12345","equity":"100"},"orders":[{"id":"o1","market":"BTC-USD"}]}}`,
+			common.GetAccountIdByNumber(testAddress, 0))))
+	})
+	defer server.Close()
+
+	client = newTestClient(server)
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	ch, err := client.SubscribeAccount(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-ch:
+		if msg.Account == nil || msg.Account.PositionId != 12345 || msg.Orders[0].ID != "o1" {
+			t.Errorf("unexpected account message %+v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for account message")
+	}
+
+	client.Close()
+	if _, ok := <-ch; ok {
+		t.Error("expected channel to be closed after Close")
+	}
+}
+
+func TestSubscribeMultipleAccounts(t *testing.T) {
+	server := newTestServer(t, func(conn *websocket.Conn, request map[string]interface{}) {
+		accountNumber, _ := strconv.Atoi(request["accountNumber"].(string))
+		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"type":"subscribed","channel":"v3_accounts","id":"%s","message_id":%d,
+			"contents":{"account":{"positionId":"%d"}}}`, common.GetAccountIdByNumber(testAddress, accountNumber), accountNumber+1, accountNumber+100)))
+	})
+	defer server.Close()
+
+	client := newTestClient(server)
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	first, err := client.SubscribeAccount(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.SubscribeAccount(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ch := range []<-chan *types.WsAccountMessage{first, second} {
+		select {
+		case msg := <-ch:
+			if msg.Account == nil || msg.Account.PositionId != int64(i+100) {
+				t.Errorf("account %d received %+v", i, msg.Account)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for account %d", i)
+		}
+	}
+	if err = client.UnsubscribeAccount(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-second; ok {
+		t.Error("expected account 1 channel to be closed after UnsubscribeAccount")
+	}
+}
+
+func TestReconnectResubscribes(t *testing.T) {
+	var mu sync.Mutex
+	connections := 0
+	server := newTestServer(t, func(conn *websocket.Conn, request map[string]interface{}) {
+		mu.Lock()
+		connections++
+		count := connections
+		mu.Unlock()
+		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"type":"subscribed","channel":"v3_trades","id":"BTC-USD","message_id":1,
+			"contents":{"trades":[{"side":"BUY","size":"%d","price":"100"}]}}`, count)))
+		if count == 1 {
+			// 第一次连接在推送快照后断开
+			conn.Close()
+		}
+	})
+	defer server.Close()
+
+	client := newTestClient(server)
+	client.MinReconnectBackoff = 10 * time.Millisecond
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	ch, err := client.SubscribeTrades("BTC-USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range []string{"1", "2"} {
+		select {
+		case msg := <-ch:
+			if !msg.Snapshot || msg.Trades[0].Size != size {
+				t.Errorf("unexpected trades message %+v", msg)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for trades message")
+		}
+	}
+
+	events := client.Events()
+	for _, expected := range []string{EventDisconnected, EventReconnected} {
+		select {
+		case event := <-events:
+			if event.Type != expected {
+				t.Errorf("expected event %s, got %+v", expected, event)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for event %s", expected)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package ws
+
+import (
+	"errors"
+	"github.com/verichenn/dydx-v3-go/common"
+	"github.com/verichenn/dydx-v3-go/modules"
+	"github.com/verichenn/dydx-v3-go/types"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+var (
+	ErrCrossedBook = errors.New("orderbook crossed")
+	// ErrNotSynced 尚未收到快照或等待重新同步时收到增量
+	ErrNotSynced = errors.New("orderbook update received before snapshot")
+)
+
+// Orderbook 基于 v3_orderbook 频道维护的本地 L2 订单簿, 可在多个 goroutine 中安全读取
+type Orderbook struct {
+	Market string
+	Client *Client
+	Public *modules.Public
+
+	mu        sync.RWMutex
+	synced    bool
+	resyncing bool
+	offset    int64
+	bids      map[string]*bookLevel
+	asks      map[string]*bookLevel
+}
+
+type bookLevel struct {
+	price  float64
+	order  types.OrderbookOrder
+	offset int64
+}
+
+func NewOrderbook(client *Client, public *modules.Public, market string) *Orderbook {
+	return &Orderbook{
+		Market: market,
+		Client: client,
+		Public: public,
+		bids:   map[string]*bookLevel{},
+		asks:   map[string]*bookLevel{},
+	}
+}
+
+// Start 订阅订单簿频道并在后台持续应用消息, 频道关闭时停止
+func (b *Orderbook) Start() error {
+	ch, err := b.Client.SubscribeOrderbook(b.Market)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for msg := range ch {
+			if err := b.Apply(msg); err != nil {
+				if errors.Is(err, ErrNotSynced) && b.isResyncing() {
+					// 等待重新订阅的快照, 期间的增量直接丢弃
+					continue
+				}
+				b.Client.Logger.Printf("orderbook %s: %v, resync", b.Market, err)
+				if err = b.Resync(); err != nil {
+					b.Client.Logger.Printf("orderbook %s resync error: %v", b.Market, err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Apply 应用一条快照或增量, offset 不大于本地档位 offset 的增量视为过期并丢弃;
+// 未同步时收到增量或应用后盘口交叉会返回错误, 调用方应执行 Resync.
+// message id 按连接计数, 由 Client 检测丢包并重新订阅, 这里不做检查
+func (b *Orderbook) Apply(msg *types.WsOrderbookMessage) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if msg.Snapshot {
+		b.bids = map[string]*bookLevel{}
+		b.asks = map[string]*bookLevel{}
+		b.offset = 0
+		for _, order := range msg.Bids {
+			b.applyLevel(b.bids, order)
+		}
+		for _, order := range msg.Asks {
+			b.applyLevel(b.asks, order)
+		}
+		b.synced, b.resyncing = true, false
+		return b.checkCrossed()
+	}
+
+	if !b.synced {
+		return ErrNotSynced
+	}
+	offset, err := strconv.ParseInt(msg.Offset, 10, 64)
+	if err != nil {
+		return err
+	}
+	if offset <= b.offset {
+		return nil
+	}
+	for _, order := range msg.Bids {
+		b.applyLevel(b.bids, order)
+	}
+	for _, order := range msg.Asks {
+		b.applyLevel(b.asks, order)
+	}
+	return b.checkCrossed()
+}
+
+// Resync 重新同步订单簿. 有 websocket Client 时重新订阅, 由新的带 offset 的快照重建, 期间的增量被丢弃;
+// 否则通过 REST 拉取全量. REST 数据没有 offset, 只能以本地最新 offset 作为基准,
+// 此时早于 REST 快照、但 offset 更大的增量仍会覆盖较新的档位, 仅适合没有 websocket 的场景
+func (b *Orderbook) Resync() error {
+	if b.Client != nil {
+		b.mu.Lock()
+		b.synced, b.resyncing = false, true
+		b.mu.Unlock()
+		return b.Client.Resubscribe(ChannelOrderbook, b.Market)
+	}
+	return b.resyncFromRest()
+}
+
+func (b *Orderbook) resyncFromRest() error {
+	data, err := b.Public.GetOrderBook(b.Market)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bids = map[string]*bookLevel{}
+	b.asks = map[string]*bookLevel{}
+	offset := strconv.FormatInt(b.offset, 10)
+	for _, order := range data.Bids {
+		order.Offset = offset
+		b.applyLevel(b.bids, order)
+	}
+	for _, order := range data.Asks {
+		order.Offset = offset
+		b.applyLevel(b.asks, order)
+	}
+	b.synced = true
+	return nil
+}
+
+func (b *Orderbook) isResyncing() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.resyncing
+}
+
+// BestBid 买一
+func (b *Orderbook) BestBid() (types.OrderbookOrder, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	level := best(b.bids, true)
+	if level == nil {
+		return types.OrderbookOrder{}, false
+	}
+	return level.order, true
+}
+
+// BestAsk 卖一
+func (b *Orderbook) BestAsk() (types.OrderbookOrder, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	level := best(b.asks, false)
+	if level == nil {
+		return types.OrderbookOrder{}, false
+	}
+	return level.order, true
+}
+
+// DepthAt 查询指定方向和价格的挂单量, 不存在时返回 "0"
+func (b *Orderbook) DepthAt(side, price string) string {
+	key, _, err := priceKey(price)
+	if err != nil {
+		return "0"
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	levels := b.asks
+	if side == common.OrderSideBuy {
+		levels = b.bids
+	}
+	if level, ok := levels[key]; ok {
+		return level.order.Size
+	}
+	return "0"
+}
+
+// Snapshot 返回当前订单簿的副本, 买盘价格从高到低, 卖盘价格从低到高
+func (b *Orderbook) Snapshot() *types.OrderbookResponse {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return &types.OrderbookResponse{
+		Offset: strconv.FormatInt(b.offset, 10),
+		Bids:   sortedLevels(b.bids, true),
+		Asks:   sortedLevels(b.asks, false),
+	}
+}
+
+func (b *Orderbook) applyLevel(levels map[string]*bookLevel, order types.OrderbookOrder) {
+	key, price, err := priceKey(order.Price)
+	if err != nil {
+		return
+	}
+	offset, _ := strconv.ParseInt(order.Offset, 10, 64)
+	if offset > b.offset {
+		b.offset = offset
+	}
+	if existing, ok := levels[key]; ok && offset <= existing.offset {
+		return
+	}
+	if size, err := strconv.ParseFloat(order.Size, 64); err != nil || size == 0 {
+		delete(levels, key)
+		return
+	}
+	levels[key] = &bookLevel{price: price, order: order, offset: offset}
+}
+
+func (b *Orderbook) checkCrossed() error {
+	bid, ask := best(b.bids, true), best(b.asks, false)
+	if bid != nil && ask != nil && bid.price >= ask.price {
+		b.synced = false
+		return ErrCrossedBook
+	}
+	return nil
+}
+
+func best(levels map[string]*bookLevel, highest bool) *bookLevel {
+	var result *bookLevel
+	for _, level := range levels {
+		if result == nil || (highest && level.price > result.price) || (!highest && level.price < result.price) {
+			result = level
+		}
+	}
+	return result
+}
+
+func sortedLevels(levels map[string]*bookLevel, descending bool) []types.OrderbookOrder {
+	sorted := make([]*bookLevel, 0, len(levels))
+	for _, level := range levels {
+		sorted = append(sorted, level)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if descending {
+			return sorted[i].price > sorted[j].price
+		}
+		return sorted[i].price < sorted[j].price
+	})
+	orders := make([]types.OrderbookOrder, len(sorted))
+	for i, level := range sorted {
+		orders[i] = level.order
+	}
+	return orders
+}
+
+func priceKey(price string) (string, float64, error) {
+	value, err := strconv.ParseFloat(price, 64)
+	if err != nil {
+		return "", 0, err
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64), value, nil
+}
@@ -0,0 +1,206 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/verichenn/dydx-v3-go/common"
+	"github.com/verichenn/dydx-v3-go/modules"
+	"github.com/verichenn/dydx-v3-go/types"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOrderbookApply(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"bids":[{"price":"99","size":"3"}],"asks":[{"price":"102","size":"4"}]}`)
+	}))
+	defer server.Close()
+	public := &modules.Public{Host: server.URL, Logger: log.New(os.Stderr, "dydx-v3-go ", log.LstdFlags)}
+	book := NewOrderbook(nil, public, "BTC-USD")
+
+	if err := book.Apply(&types.WsOrderbookMessage{Offset: "1"}); err == nil {
+		t.Error("expected error for update before snapshot")
+	}
+	err := book.Apply(&types.WsOrderbookMessage{
+		Snapshot: true,
+		Bids:     []types.OrderbookOrder{{Price: "100", Size: "1", Offset: "10"}, {Price: "99.5", Size: "2", Offset: "5"}},
+		Asks:     []types.OrderbookOrder{{Price: "101", Size: "1", Offset: "8"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 过期档位被丢弃, 较新的档位被更新
+	err = book.Apply(&types.WsOrderbookMessage{
+		Offset: "11",
+		Bids:   []types.OrderbookOrder{{Price: "99.50", Size: "0", Offset: "11"}},
+		Asks:   []types.OrderbookOrder{{Price: "101", Size: "5", Offset: "11"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	book.Apply(&types.WsOrderbookMessage{Offset: "9", Bids: []types.OrderbookOrder{{Price: "100", Size: "9", Offset: "9"}}})
+	if book.DepthAt(common.OrderSideBuy, "100") != "1" {
+		t.Error("stale update should be discarded")
+	}
+	if depth := book.DepthAt(common.OrderSideBuy, "99.5"); depth != "0" {
+		t.Errorf("expected removed level, got %s", depth)
+	}
+	if ask, _ := book.BestAsk(); ask.Size != "5" {
+		t.Errorf("unexpected best ask %+v", ask)
+	}
+
+	// 盘口交叉后通过 REST 重新同步
+	err = book.Apply(&types.WsOrderbookMessage{Offset: "12", Bids: []types.OrderbookOrder{{Price: "101.5", Size: "1", Offset: "12"}}})
+	if err != ErrCrossedBook {
+		t.Fatalf("expected crossed book, got %v", err)
+	}
+	if err = book.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := book.Snapshot()
+	if len(snapshot.Bids) != 1 || snapshot.Bids[0].Price != "99" || snapshot.Asks[0].Price != "102" || snapshot.Offset != "12" {
+		t.Errorf("unexpected snapshot after resync %+v", snapshot)
+	}
+	if bid, _ := book.BestBid(); bid.Price != "99" {
+		t.Errorf("unexpected best bid %+v", bid)
+	}
+}
+
+func TestOrderbookInterleavedChannels(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	server := newTestServer(t, func(conn *websocket.Conn, request map[string]interface{}) {
+		mu.Lock()
+		requests = append(requests, request["type"].(string)+":"+request["channel"].(string))
+		mu.Unlock()
+		if request["channel"] == ChannelTrades {
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"subscribed","channel":"v3_trades","id":"BTC-USD","message_id":2,
+				"contents":{"trades":[{"side":"BUY","size":"1","price":"100"}]}}`))
+			// 同一连接上两个频道的消息交替推送, message id 按连接递增
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"channel_data","channel":"v3_orderbook","id":"BTC-USD","message_id":3,
+				"contents":{"offset":"11","bids":[["99","2"]],"asks":[]}}`))
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"channel_data","channel":"v3_trades","id":"BTC-USD","message_id":4,
+				"contents":{"trades":[{"side":"SELL","size":"2","price":"100"}]}}`))
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"channel_data","channel":"v3_orderbook","id":"BTC-USD","message_id":5,
+				"contents":{"offset":"12","bids":[["98","3"]],"asks":[]}}`))
+			return
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"subscribed","channel":"v3_orderbook","id":"BTC-USD","message_id":1,
+			"contents":{"bids":[{"price":"100","size":"1","offset":"10"}],"asks":[{"price":"101","size":"2","offset":"10"}]}}`))
+	})
+	defer server.Close()
+
+	client := newTestClient(server)
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	book := NewOrderbook(client, nil, "BTC-USD")
+	if err := book.Start(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := book.BestBid(); ok {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	trades, err := client.SubscribeTrades("BTC-USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-trades:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for trades message")
+		}
+	}
+	for time.Now().Before(deadline) {
+		if book.DepthAt(common.OrderSideBuy, "98") == "3" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if snapshot := book.Snapshot(); len(snapshot.Bids) != 3 || snapshot.Offset != "12" {
+		t.Errorf("expected both updates applied, got %+v", snapshot)
+	}
+	select {
+	case event := <-client.Events():
+		t.Errorf("unexpected event %+v", event)
+	default:
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(requests, ",") != "subscribe:v3_orderbook,subscribe:v3_trades" {
+		t.Errorf("unexpected requests %v", requests)
+	}
+}
+
+func TestMessageGapResubscribes(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	server := newTestServer(t, func(conn *websocket.Conn, request map[string]interface{}) {
+		mu.Lock()
+		requests = append(requests, request["type"].(string))
+		subscriptions := len(requests)
+		mu.Unlock()
+		if request["type"] != "subscribe" {
+			return
+		}
+		if subscriptions == 1 {
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"subscribed","channel":"v3_orderbook","id":"BTC-USD","message_id":1,
+				"contents":{"bids":[{"price":"100","size":"1","offset":"10"}],"asks":[{"price":"101","size":"2","offset":"10"}]}}`))
+			// message 2 丢失
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"channel_data","channel":"v3_orderbook","id":"BTC-USD","message_id":3,
+				"contents":{"offset":"12","bids":[["99","1"]],"asks":[]}}`))
+			return
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"subscribed","channel":"v3_orderbook","id":"BTC-USD","message_id":4,
+			"contents":{"bids":[{"price":"98","size":"3","offset":"20"}],"asks":[{"price":"102","size":"1","offset":"20"}]}}`))
+	})
+	defer server.Close()
+
+	client := newTestClient(server)
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	book := NewOrderbook(client, nil, "BTC-USD")
+	if err := book.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if bid, ok := book.BestBid(); ok && bid.Price == "98" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if snapshot := book.Snapshot(); len(snapshot.Bids) != 1 || snapshot.Bids[0].Price != "98" || snapshot.Offset != "20" {
+		t.Errorf("expected book rebuilt from new snapshot, got %+v", snapshot)
+	}
+	select {
+	case event := <-client.Events():
+		if event.Type != EventMessageGap || !errors.Is(event.Err, ErrMessageGap) {
+			t.Errorf("unexpected event %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for message gap event")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(requests, ",") != "subscribe,unsubscribe,subscribe" {
+		t.Errorf("unexpected requests %v", requests)
+	}
+}